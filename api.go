@@ -19,6 +19,18 @@ const (
 	POST ReqMethod = "POST"
 )
 
+// Standard API headers, in canonical format.
+const (
+	// Fork name of the versioned object in the request or response body.
+	ConsensusVersionHeader = "Eth-Consensus-Version"
+	// Set to "true" if the produced block contains a blinded execution payload, "false" otherwise.
+	ExecutionPayloadBlindedHeader = "Eth-Execution-Payload-Blinded"
+	// Value of the execution payload of the produced block, in wei, as decimal string.
+	ExecutionPayloadValueHeader = "Eth-Execution-Payload-Value"
+	// Value of the consensus rewards of the produced block, in gwei, as decimal string.
+	ConsensusBlockValueHeader = "Eth-Consensus-Block-Value"
+)
+
 // DataWrap is a util to accommodate responses which are wrapped
 // with a single field container with key "data".
 type DataWrap struct {
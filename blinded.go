@@ -0,0 +1,325 @@
+package eth2api
+
+import (
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	"github.com/protolambda/zrnt/eth2/beacon/capella"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/beacon/electra"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
+)
+
+// BlindedBeaconBlock is a block with only the header of the execution payload, for the builder flow.
+// The hash-tree-root matches that of the full block.
+type BlindedBeaconBlock struct {
+	Slot          common.Slot           `json:"slot"`
+	ProposerIndex common.ValidatorIndex `json:"proposer_index"`
+	ParentRoot    common.Root           `json:"parent_root"`
+	StateRoot     common.Root           `json:"state_root"`
+	// Body is *BellatrixBlindedBeaconBlockBody, *CapellaBlindedBeaconBlockBody,
+	// *DenebBlindedBeaconBlockBody or *ElectraBlindedBeaconBlockBody.
+	Body common.SpecObj `json:"body"`
+}
+
+func (b *BlindedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(&b.Slot, &b.ProposerIndex, &b.ParentRoot, &b.StateRoot, spec.Wrap(b.Body))
+}
+
+func (b *BlindedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(&b.Slot, &b.ProposerIndex, &b.ParentRoot, &b.StateRoot, spec.Wrap(b.Body))
+}
+
+func (b *BlindedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(&b.Slot, &b.ProposerIndex, &b.ParentRoot, &b.StateRoot, spec.Wrap(b.Body))
+}
+
+func (b *BlindedBeaconBlock) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *BlindedBeaconBlock) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(b.Slot, b.ProposerIndex, b.ParentRoot, b.StateRoot, spec.Wrap(b.Body))
+}
+
+// SignedBlindedBeaconBlock is a signed blinded block, to publish in the builder flow.
+type SignedBlindedBeaconBlock struct {
+	Message   BlindedBeaconBlock  `json:"message"`
+	Signature common.BLSSignature `json:"signature"`
+}
+
+func (b *SignedBlindedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(spec.Wrap(&b.Message), &b.Signature)
+}
+
+func (b *SignedBlindedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(spec.Wrap(&b.Message), &b.Signature)
+}
+
+func (b *SignedBlindedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(spec.Wrap(&b.Message), &b.Signature)
+}
+
+func (b *SignedBlindedBeaconBlock) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *SignedBlindedBeaconBlock) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(spec.Wrap(&b.Message), b.Signature)
+}
+
+// BellatrixBlindedBeaconBlockBody is the blinded block body of bellatrix.
+type BellatrixBlindedBeaconBlockBody struct {
+	RandaoReveal           common.BLSSignature              `json:"randao_reveal"`
+	Eth1Data               common.Eth1Data                  `json:"eth1_data"`
+	Graffiti               common.Root                      `json:"graffiti"`
+	ProposerSlashings      phase0.ProposerSlashings         `json:"proposer_slashings"`
+	AttesterSlashings      phase0.AttesterSlashings         `json:"attester_slashings"`
+	Attestations           phase0.Attestations              `json:"attestations"`
+	Deposits               phase0.Deposits                  `json:"deposits"`
+	VoluntaryExits         phase0.VoluntaryExits            `json:"voluntary_exits"`
+	SyncAggregate          altair.SyncAggregate             `json:"sync_aggregate"`
+	ExecutionPayloadHeader bellatrix.ExecutionPayloadHeader `json:"execution_payload_header"`
+}
+
+func (b *BellatrixBlindedBeaconBlockBody) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+	)
+}
+
+func (b *BellatrixBlindedBeaconBlockBody) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+	)
+}
+
+func (b *BellatrixBlindedBeaconBlockBody) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+	)
+}
+
+func (b *BellatrixBlindedBeaconBlockBody) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *BellatrixBlindedBeaconBlockBody) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(
+		b.RandaoReveal, &b.Eth1Data,
+		b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+	)
+}
+
+// CapellaBlindedBeaconBlockBody is the blinded block body of capella.
+type CapellaBlindedBeaconBlockBody struct {
+	RandaoReveal           common.BLSSignature                `json:"randao_reveal"`
+	Eth1Data               common.Eth1Data                    `json:"eth1_data"`
+	Graffiti               common.Root                        `json:"graffiti"`
+	ProposerSlashings      phase0.ProposerSlashings           `json:"proposer_slashings"`
+	AttesterSlashings      phase0.AttesterSlashings           `json:"attester_slashings"`
+	Attestations           phase0.Attestations                `json:"attestations"`
+	Deposits               phase0.Deposits                    `json:"deposits"`
+	VoluntaryExits         phase0.VoluntaryExits              `json:"voluntary_exits"`
+	SyncAggregate          altair.SyncAggregate               `json:"sync_aggregate"`
+	ExecutionPayloadHeader capella.ExecutionPayloadHeader     `json:"execution_payload_header"`
+	BLSToExecutionChanges  common.SignedBLSToExecutionChanges `json:"bls_to_execution_changes"`
+}
+
+func (b *CapellaBlindedBeaconBlockBody) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges),
+	)
+}
+
+func (b *CapellaBlindedBeaconBlockBody) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges),
+	)
+}
+
+func (b *CapellaBlindedBeaconBlockBody) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges),
+	)
+}
+
+func (b *CapellaBlindedBeaconBlockBody) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *CapellaBlindedBeaconBlockBody) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(
+		b.RandaoReveal, &b.Eth1Data,
+		b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges),
+	)
+}
+
+// DenebBlindedBeaconBlockBody is the blinded block body of deneb.
+type DenebBlindedBeaconBlockBody struct {
+	RandaoReveal           common.BLSSignature                `json:"randao_reveal"`
+	Eth1Data               common.Eth1Data                    `json:"eth1_data"`
+	Graffiti               common.Root                        `json:"graffiti"`
+	ProposerSlashings      phase0.ProposerSlashings           `json:"proposer_slashings"`
+	AttesterSlashings      phase0.AttesterSlashings           `json:"attester_slashings"`
+	Attestations           phase0.Attestations                `json:"attestations"`
+	Deposits               phase0.Deposits                    `json:"deposits"`
+	VoluntaryExits         phase0.VoluntaryExits              `json:"voluntary_exits"`
+	SyncAggregate          altair.SyncAggregate               `json:"sync_aggregate"`
+	ExecutionPayloadHeader deneb.ExecutionPayloadHeader       `json:"execution_payload_header"`
+	BLSToExecutionChanges  common.SignedBLSToExecutionChanges `json:"bls_to_execution_changes"`
+	BlobKZGCommitments     deneb.KZGCommitments               `json:"blob_kzg_commitments"`
+}
+
+func (b *DenebBlindedBeaconBlockBody) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+	)
+}
+
+func (b *DenebBlindedBeaconBlockBody) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+	)
+}
+
+func (b *DenebBlindedBeaconBlockBody) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+	)
+}
+
+func (b *DenebBlindedBeaconBlockBody) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *DenebBlindedBeaconBlockBody) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(
+		b.RandaoReveal, &b.Eth1Data,
+		b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+	)
+}
+
+// ElectraBlindedBeaconBlockBody is the blinded block body of electra.
+type ElectraBlindedBeaconBlockBody struct {
+	RandaoReveal           common.BLSSignature                `json:"randao_reveal"`
+	Eth1Data               common.Eth1Data                    `json:"eth1_data"`
+	Graffiti               common.Root                        `json:"graffiti"`
+	ProposerSlashings      phase0.ProposerSlashings           `json:"proposer_slashings"`
+	AttesterSlashings      electra.AttesterSlashings          `json:"attester_slashings"`
+	Attestations           electra.Attestations               `json:"attestations"`
+	Deposits               phase0.Deposits                    `json:"deposits"`
+	VoluntaryExits         phase0.VoluntaryExits              `json:"voluntary_exits"`
+	SyncAggregate          altair.SyncAggregate               `json:"sync_aggregate"`
+	ExecutionPayloadHeader deneb.ExecutionPayloadHeader       `json:"execution_payload_header"`
+	BLSToExecutionChanges  common.SignedBLSToExecutionChanges `json:"bls_to_execution_changes"`
+	BlobKZGCommitments     deneb.KZGCommitments               `json:"blob_kzg_commitments"`
+	ExecutionRequests      electra.ExecutionRequests          `json:"execution_requests"`
+}
+
+func (b *ElectraBlindedBeaconBlockBody) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+		spec.Wrap(&b.ExecutionRequests),
+	)
+}
+
+func (b *ElectraBlindedBeaconBlockBody) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+		spec.Wrap(&b.ExecutionRequests),
+	)
+}
+
+func (b *ElectraBlindedBeaconBlockBody) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(
+		&b.RandaoReveal, &b.Eth1Data,
+		&b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+		spec.Wrap(&b.ExecutionRequests),
+	)
+}
+
+func (b *ElectraBlindedBeaconBlockBody) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *ElectraBlindedBeaconBlockBody) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(
+		b.RandaoReveal, &b.Eth1Data,
+		b.Graffiti, spec.Wrap(&b.ProposerSlashings),
+		spec.Wrap(&b.AttesterSlashings), spec.Wrap(&b.Attestations),
+		spec.Wrap(&b.Deposits), spec.Wrap(&b.VoluntaryExits),
+		spec.Wrap(&b.SyncAggregate), &b.ExecutionPayloadHeader,
+		spec.Wrap(&b.BLSToExecutionChanges), spec.Wrap(&b.BlobKZGCommitments),
+		spec.Wrap(&b.ExecutionRequests),
+	)
+}
@@ -0,0 +1,138 @@
+package eth2api
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	"github.com/protolambda/zrnt/eth2/beacon/capella"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/beacon/electra"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
+	"github.com/protolambda/ztyp/view"
+)
+
+// blinds the default block of each fork, by replacing the execution payload with its header
+var blindDefaultBlock = map[string]func(t *testing.T, spec *common.Spec) (full common.SpecObj, blinded *BlindedBeaconBlock){
+	"bellatrix": func(t *testing.T, spec *common.Spec) (common.SpecObj, *BlindedBeaconBlock) {
+		var full bellatrix.BeaconBlock
+		decodeDefault(t, spec, bellatrix.BeaconBlockType(spec).New(), &full)
+		return &full, &BlindedBeaconBlock{Body: &BellatrixBlindedBeaconBlockBody{
+			SyncAggregate:          full.Body.SyncAggregate,
+			ExecutionPayloadHeader: *full.Body.ExecutionPayload.Header(spec),
+		}}
+	},
+	"capella": func(t *testing.T, spec *common.Spec) (common.SpecObj, *BlindedBeaconBlock) {
+		var full capella.BeaconBlock
+		decodeDefault(t, spec, capella.BeaconBlockType(spec).New(), &full)
+		return &full, &BlindedBeaconBlock{Body: &CapellaBlindedBeaconBlockBody{
+			SyncAggregate:          full.Body.SyncAggregate,
+			ExecutionPayloadHeader: *full.Body.ExecutionPayload.Header(spec),
+		}}
+	},
+	"deneb": func(t *testing.T, spec *common.Spec) (common.SpecObj, *BlindedBeaconBlock) {
+		var full deneb.BeaconBlock
+		decodeDefault(t, spec, deneb.BeaconBlockType(spec).New(), &full)
+		return &full, &BlindedBeaconBlock{Body: &DenebBlindedBeaconBlockBody{
+			SyncAggregate:          full.Body.SyncAggregate,
+			ExecutionPayloadHeader: *full.Body.ExecutionPayload.Header(spec),
+		}}
+	},
+	"electra": func(t *testing.T, spec *common.Spec) (common.SpecObj, *BlindedBeaconBlock) {
+		var full electra.BeaconBlock
+		decodeDefault(t, spec, electra.BeaconBlockType(spec).New(), &full)
+		return &full, &BlindedBeaconBlock{Body: &ElectraBlindedBeaconBlockBody{
+			SyncAggregate:          full.Body.SyncAggregate,
+			ExecutionPayloadHeader: *full.Body.ExecutionPayload.Header(spec),
+		}}
+	},
+}
+
+func decodeDefault(t *testing.T, spec *common.Spec, v interface {
+	Serialize(w *codec.EncodingWriter) error
+}, dest common.SpecObj) {
+	var buf bytes.Buffer
+	if err := v.Serialize(codec.NewEncodingWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dest.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(buf.Bytes()), uint64(buf.Len()))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBlindedBlocks(t *testing.T) {
+	spec := configs.Minimal
+	hFn := tree.GetHashFn()
+	for _, f := range Forks {
+		blind, ok := blindDefaultBlock[f.Name]
+		if !ok {
+			if f.BlindedBeaconBlockBody != nil {
+				t.Fatalf("fork %s: blinded blocks are not tested", f.Name)
+			}
+			if _, err := newBlindedBeaconBlock(f.Name); err == nil {
+				t.Fatalf("fork %s: expected no blinded blocks", f.Name)
+			}
+			continue
+		}
+		full, blinded := blind(t, spec)
+		if blinded.HashTreeRoot(spec, hFn) != full.HashTreeRoot(spec, hFn) {
+			t.Fatalf("fork %s: blinded block root does not match full block root", f.Name)
+		}
+		signed := &SignedBlindedBeaconBlock{Message: *blinded, Signature: common.BLSSignature{0xaa}}
+		for _, c := range []struct {
+			name  string
+			obj   VersionedSpecObj
+			alloc func() VersionedSpecObj
+		}{
+			{"blinded block", &VersionedBlindedBeaconBlock{Version: f.Name, Data: blinded},
+				func() VersionedSpecObj { return new(VersionedBlindedBeaconBlock) }},
+			{"signed blinded block", &VersionedSignedBlindedBeaconBlock{Version: f.Name, Data: signed},
+				func() VersionedSpecObj { return new(VersionedSignedBlindedBeaconBlock) }},
+			// SSZ does not encode if the block is blinded, it is preset like the version
+			{"produced block", &ProducedBlock{Version: f.Name, ExecutionPayloadBlinded: true, Data: blinded},
+				func() VersionedSpecObj { return &ProducedBlock{ExecutionPayloadBlinded: true} }},
+		} {
+			root := c.obj.HashTreeRoot(spec, hFn)
+			version, data, err := MarshalVersionedSSZ(spec, c.obj)
+			if err != nil {
+				t.Fatalf("fork %s: %s: %v", f.Name, c.name, err)
+			}
+			fromSSZ := c.alloc()
+			if err := UnmarshalVersionedSSZ(spec, version, data, fromSSZ); err != nil {
+				t.Fatalf("fork %s: %s: %v", f.Name, c.name, err)
+			}
+			if fromSSZ.HashTreeRoot(spec, hFn) != root {
+				t.Fatalf("fork %s: %s did not round-trip through SSZ", f.Name, c.name)
+			}
+			jsonData, err := json.Marshal(fromSSZ)
+			if err != nil {
+				t.Fatalf("fork %s: %s: %v", f.Name, c.name, err)
+			}
+			fromJSON := c.alloc()
+			if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+				t.Fatalf("fork %s: %s: %v", f.Name, c.name, err)
+			}
+			if fromJSON.HashTreeRoot(spec, hFn) != root {
+				t.Fatalf("fork %s: %s did not round-trip through JSON", f.Name, c.name)
+			}
+		}
+	}
+}
+
+func TestProducedBlockHeaders(t *testing.T) {
+	block := &ProducedBlock{Version: "deneb", ExecutionPayloadBlinded: true}
+	if err := block.ExecutionPayloadValue.UnmarshalText([]byte("123456789012345678901234567890")); err != nil {
+		t.Fatal(err)
+	}
+	block.ConsensusBlockValue = view.MustUint256("42")
+	var got ProducedBlock
+	got.PresetHeaders(block.Headers())
+	if got.Version != "deneb" || !got.ExecutionPayloadBlinded ||
+		got.ExecutionPayloadValue != block.ExecutionPayloadValue || got.ConsensusBlockValue != block.ConsensusBlockValue {
+		t.Fatalf("unexpected produced block from headers: %v", got)
+	}
+}
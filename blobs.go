@@ -0,0 +1,415 @@
+package eth2api
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/util/merkle"
+	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
+	"github.com/protolambda/ztyp/view"
+)
+
+const KZGProofSize = 48
+
+type KZGProof [KZGProofSize]byte
+
+func (p *KZGProof) Deserialize(dr *codec.DecodingReader) error {
+	if p == nil {
+		return errors.New("nil KZG proof")
+	}
+	_, err := dr.Read(p[:])
+	return err
+}
+
+func (p *KZGProof) Serialize(w *codec.EncodingWriter) error {
+	return w.Write(p[:])
+}
+
+func (KZGProof) ByteLength() uint64 {
+	return KZGProofSize
+}
+
+func (KZGProof) FixedLength() uint64 {
+	return KZGProofSize
+}
+
+func (p KZGProof) HashTreeRoot(hFn tree.HashFn) tree.Root {
+	var a, b tree.Root
+	copy(a[:], p[0:32])
+	copy(b[:], p[32:48])
+	return hFn(a, b)
+}
+
+func (p KZGProof) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(p[:])), nil
+}
+
+func (p KZGProof) String() string {
+	return "0x" + hex.EncodeToString(p[:])
+}
+
+func (p *KZGProof) UnmarshalText(text []byte) error {
+	if p == nil {
+		return errors.New("cannot decode into nil KZGProof")
+	}
+	if len(text) >= 2 && text[0] == '0' && (text[1] == 'x' || text[1] == 'X') {
+		text = text[2:]
+	}
+	if len(text) != 2*KZGProofSize {
+		return fmt.Errorf("unexpected length string '%s'", string(text))
+	}
+	_, err := hex.Decode(p[:], text)
+	return err
+}
+
+// KZGProofs is a list of KZG proofs, one for each blob of a block.
+type KZGProofs []KZGProof
+
+func (li *KZGProofs) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.List(func() codec.Deserializable {
+		i := len(*li)
+		*li = append(*li, KZGProof{})
+		return &((*li)[i])
+	}, KZGProofSize, uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li KZGProofs) Serialize(_ *common.Spec, w *codec.EncodingWriter) error {
+	return w.List(func(i uint64) codec.Serializable {
+		return &li[i]
+	}, KZGProofSize, uint64(len(li)))
+}
+
+func (li KZGProofs) ByteLength(_ *common.Spec) uint64 {
+	return KZGProofSize * uint64(len(li))
+}
+
+func (*KZGProofs) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (li KZGProofs) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	length := uint64(len(li))
+	return hFn.ComplexListHTR(func(i uint64) tree.HTR {
+		if i < length {
+			return &li[i]
+		}
+		return nil
+	}, length, uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li KZGProofs) MarshalJSON() ([]byte, error) {
+	if li == nil {
+		return json.Marshal([]KZGProof{}) // encode as empty list, not null
+	}
+	return json.Marshal([]KZGProof(li))
+}
+
+// BlobSize returns the byte size of a blob in the given spec.
+func BlobSize(spec *common.Spec) uint64 {
+	return uint64(spec.FIELD_ELEMENTS_PER_BLOB) * 32
+}
+
+// Blob is the data of a blob, of BlobSize bytes.
+type Blob []byte
+
+func (b *Blob) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	*b = make(Blob, BlobSize(spec))
+	_, err := dr.Read(*b)
+	return err
+}
+
+func (b Blob) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if size := BlobSize(spec); uint64(len(b)) != size {
+		return fmt.Errorf("blob has %d bytes, expected %d", len(b), size)
+	}
+	return w.Write(b)
+}
+
+func (b Blob) ByteLength(spec *common.Spec) uint64 {
+	return BlobSize(spec)
+}
+
+func (b *Blob) FixedLength(spec *common.Spec) uint64 {
+	return BlobSize(spec)
+}
+
+func (b Blob) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.ByteVectorHTR(b)
+}
+
+func (b Blob) MarshalText() ([]byte, error) {
+	return []byte("0x" + hex.EncodeToString(b)), nil
+}
+
+func (b *Blob) UnmarshalText(text []byte) error {
+	if b == nil {
+		return errors.New("cannot decode into nil Blob")
+	}
+	if len(text) >= 2 && text[0] == '0' && (text[1] == 'x' || text[1] == 'X') {
+		text = text[2:]
+	}
+	out := make([]byte, hex.DecodedLen(len(text)))
+	if _, err := hex.Decode(out, text); err != nil {
+		return err
+	}
+	*b = out
+	return nil
+}
+
+// Blobs is a list of blobs, as included with a block.
+type Blobs []Blob
+
+func (li *Blobs) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.List(func() codec.Deserializable {
+		i := len(*li)
+		*li = append(*li, nil)
+		return spec.Wrap(&((*li)[i]))
+	}, BlobSize(spec), uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li Blobs) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.List(func(i uint64) codec.Serializable {
+		return spec.Wrap(&li[i])
+	}, BlobSize(spec), uint64(len(li)))
+}
+
+func (li Blobs) ByteLength(spec *common.Spec) uint64 {
+	return BlobSize(spec) * uint64(len(li))
+}
+
+func (*Blobs) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (li Blobs) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	length := uint64(len(li))
+	return hFn.ComplexListHTR(func(i uint64) tree.HTR {
+		if i < length {
+			return spec.Wrap(&li[i])
+		}
+		return nil
+	}, length, uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li Blobs) MarshalJSON() ([]byte, error) {
+	if li == nil {
+		return json.Marshal([]Blob{}) // encode as empty list, not null
+	}
+	return json.Marshal([]Blob(li))
+}
+
+// BlockContents is an unsigned block with the blobs and KZG proofs of its blob commitments,
+// as produced for block proposals since deneb.
+type BlockContents struct {
+	// Block is *deneb.BeaconBlock or *electra.BeaconBlock.
+	Block     common.SpecObj `json:"block"`
+	KZGProofs KZGProofs      `json:"kzg_proofs"`
+	Blobs     Blobs          `json:"blobs"`
+}
+
+func (b *BlockContents) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(spec.Wrap(b.Block), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *BlockContents) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(spec.Wrap(b.Block), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *BlockContents) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(spec.Wrap(b.Block), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *BlockContents) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *BlockContents) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(spec.Wrap(b.Block), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+// SignedBlockContents is a signed block with the blobs and KZG proofs of its blob commitments,
+// as published since deneb.
+type SignedBlockContents struct {
+	// SignedBlock is *deneb.SignedBeaconBlock or *electra.SignedBeaconBlock.
+	SignedBlock SignedBeaconBlock `json:"signed_block"`
+	KZGProofs   KZGProofs         `json:"kzg_proofs"`
+	Blobs       Blobs             `json:"blobs"`
+}
+
+func (b *SignedBlockContents) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.Container(spec.Wrap(b.SignedBlock), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *SignedBlockContents) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.Container(spec.Wrap(b.SignedBlock), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *SignedBlockContents) ByteLength(spec *common.Spec) uint64 {
+	return codec.ContainerLength(spec.Wrap(b.SignedBlock), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+func (b *SignedBlockContents) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (b *SignedBlockContents) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(spec.Wrap(b.SignedBlock), spec.Wrap(&b.KZGProofs), spec.Wrap(&b.Blobs))
+}
+
+// KZGCommitmentInclusionProof is the merkle branch of a blob KZG commitment,
+// to the body root of the block, of KZG_COMMITMENT_INCLUSION_PROOF_DEPTH roots.
+type KZGCommitmentInclusionProof []common.Root
+
+func (p *KZGCommitmentInclusionProof) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	depth := uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH)
+	*p = make(KZGCommitmentInclusionProof, depth)
+	return dr.Vector(func(i uint64) codec.Deserializable {
+		return &(*p)[i]
+	}, 32, depth)
+}
+
+func (p KZGCommitmentInclusionProof) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if depth := uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH); uint64(len(p)) != depth {
+		return fmt.Errorf("inclusion proof has %d roots, expected %d", len(p), depth)
+	}
+	return w.Vector(func(i uint64) codec.Serializable {
+		return &p[i]
+	}, 32, uint64(len(p)))
+}
+
+func (p KZGCommitmentInclusionProof) ByteLength(spec *common.Spec) uint64 {
+	return uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH) * 32
+}
+
+func (p *KZGCommitmentInclusionProof) FixedLength(spec *common.Spec) uint64 {
+	return uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH) * 32
+}
+
+func (p KZGCommitmentInclusionProof) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.ComplexVectorHTR(func(i uint64) tree.HTR {
+		return &p[i]
+	}, uint64(len(p)))
+}
+
+// blobKZGCommitmentsField is the field index of the blob KZG commitments in the block body, since deneb.
+const blobKZGCommitmentsField = 11
+
+// BlobSidecar is a blob with its KZG commitment and proof,
+// and the header of the block that includes the commitment.
+type BlobSidecar struct {
+	Index                       view.Uint64View                `json:"index"`
+	Blob                        Blob                           `json:"blob"`
+	KZGCommitment               common.KZGCommitment           `json:"kzg_commitment"`
+	KZGProof                    KZGProof                       `json:"kzg_proof"`
+	SignedBlockHeader           common.SignedBeaconBlockHeader `json:"signed_block_header"`
+	KZGCommitmentInclusionProof KZGCommitmentInclusionProof    `json:"kzg_commitment_inclusion_proof"`
+}
+
+func (b *BlobSidecar) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.FixedLenContainer(&b.Index, spec.Wrap(&b.Blob), &b.KZGCommitment, &b.KZGProof,
+		&b.SignedBlockHeader, spec.Wrap(&b.KZGCommitmentInclusionProof))
+}
+
+func (b *BlobSidecar) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.FixedLenContainer(&b.Index, spec.Wrap(&b.Blob), &b.KZGCommitment, &b.KZGProof,
+		&b.SignedBlockHeader, spec.Wrap(&b.KZGCommitmentInclusionProof))
+}
+
+func (b *BlobSidecar) ByteLength(spec *common.Spec) uint64 {
+	return b.FixedLength(spec)
+}
+
+func (b *BlobSidecar) FixedLength(spec *common.Spec) uint64 {
+	return blobSidecarSize(spec)
+}
+
+func (b *BlobSidecar) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	return hFn.HashTreeRoot(b.Index, spec.Wrap(&b.Blob), &b.KZGCommitment, &b.KZGProof,
+		&b.SignedBlockHeader, spec.Wrap(&b.KZGCommitmentInclusionProof))
+}
+
+// VerifyInclusionProof verifies the inclusion of the KZG commitment
+// in the block body, by the body root of the signed block header.
+// The signature of the block header is not verified.
+func (b *BlobSidecar) VerifyInclusionProof(spec *common.Spec) error {
+	depth := uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH)
+	if uint64(len(b.KZGCommitmentInclusionProof)) != depth {
+		return fmt.Errorf("inclusion proof has %d roots, expected %d", len(b.KZGCommitmentInclusionProof), depth)
+	}
+	limit := uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK)
+	if uint64(b.Index) >= limit {
+		return fmt.Errorf("blob index %d out of range, limit is %d", b.Index, limit)
+	}
+	// the commitment is an element of the list, under the length mix-in, under the body field
+	listDepth := uint64(tree.CoverDepth(limit))
+	index := blobKZGCommitmentsField<<(listDepth+1) | uint64(b.Index)
+	leaf := b.KZGCommitment.HashTreeRoot(tree.GetHashFn())
+	if !merkle.VerifyMerkleBranch(leaf, b.KZGCommitmentInclusionProof, depth, index, b.SignedBlockHeader.Message.BodyRoot) {
+		return fmt.Errorf("invalid inclusion proof for blob %d", b.Index)
+	}
+	return nil
+}
+
+func blobSidecarSize(spec *common.Spec) uint64 {
+	return 8 + BlobSize(spec) + common.KZGCommitmentSize + KZGProofSize +
+		new(common.SignedBeaconBlockHeader).FixedLength() + uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH)*32
+}
+
+// BlobSidecars is a list of blob sidecars, of a single block.
+//
+// The API limits the list to the max number of blobs per block of the fork,
+// which is bounded by MAX_BLOB_COMMITMENTS_PER_BLOCK, the limit used here.
+type BlobSidecars []BlobSidecar
+
+func (li *BlobSidecars) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	return dr.List(func() codec.Deserializable {
+		i := len(*li)
+		*li = append(*li, BlobSidecar{})
+		return spec.Wrap(&((*li)[i]))
+	}, blobSidecarSize(spec), uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li BlobSidecars) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	return w.List(func(i uint64) codec.Serializable {
+		return spec.Wrap(&li[i])
+	}, blobSidecarSize(spec), uint64(len(li)))
+}
+
+func (li BlobSidecars) ByteLength(spec *common.Spec) uint64 {
+	return blobSidecarSize(spec) * uint64(len(li))
+}
+
+func (*BlobSidecars) FixedLength(*common.Spec) uint64 {
+	return 0
+}
+
+func (li BlobSidecars) HashTreeRoot(spec *common.Spec, hFn tree.HashFn) common.Root {
+	length := uint64(len(li))
+	return hFn.ComplexListHTR(func(i uint64) tree.HTR {
+		if i < length {
+			return spec.Wrap(&li[i])
+		}
+		return nil
+	}, length, uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK))
+}
+
+func (li BlobSidecars) MarshalJSON() ([]byte, error) {
+	if li == nil {
+		return json.Marshal([]BlobSidecar{}) // encode as empty list, not null
+	}
+	return json.Marshal([]BlobSidecar(li))
+}
+
+// VerifyInclusionProofs verifies the inclusion proof of every blob sidecar, see BlobSidecar.VerifyInclusionProof.
+func (li BlobSidecars) VerifyInclusionProofs(spec *common.Spec) error {
+	for i := range li {
+		if err := li[i].VerifyInclusionProof(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package eth2api
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
+	"github.com/protolambda/ztyp/view"
+)
+
+func TestBlobSidecars(t *testing.T) {
+	spec := configs.Minimal
+	hFn := tree.GetHashFn()
+
+	var body deneb.BeaconBlockBody
+	body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, spec.SYNC_COMMITTEE_SIZE/8)
+	body.BlobKZGCommitments = deneb.KZGCommitments{{0xc0}, {0xc1}, {0xc2}}
+	var buf bytes.Buffer
+	if err := body.Serialize(spec, codec.NewEncodingWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	bodyView, err := deneb.BeaconBlockBodyType(spec).Deserialize(codec.NewDecodingReader(bytes.NewReader(buf.Bytes()), uint64(buf.Len())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bodyNode := bodyView.Backing()
+	header := common.SignedBeaconBlockHeader{Message: common.BeaconBlockHeader{Slot: 42, BodyRoot: body.HashTreeRoot(spec, hFn)}}
+	if bodyNode.MerkleRoot(hFn) != header.Message.BodyRoot {
+		t.Fatal("body view does not match body")
+	}
+
+	depth := uint64(spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH)
+	listDepth := uint64(tree.CoverDepth(uint64(spec.MAX_BLOB_COMMITMENTS_PER_BLOCK)))
+	var sidecars BlobSidecars
+	for i, commitment := range body.BlobKZGCommitments {
+		// the generalized index of the commitment in the body
+		gindex := (1 << depth) | blobKZGCommitmentsField<<(listDepth+1) | uint64(i)
+		leaf, err := bodyNode.Getter(tree.Gindex64(gindex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaf.MerkleRoot(hFn) != commitment.HashTreeRoot(hFn) {
+			t.Fatalf("commitment %d not found at gindex %d", i, gindex)
+		}
+		proof := make(KZGCommitmentInclusionProof, depth)
+		for d := range proof {
+			sibling, err := bodyNode.Getter(tree.Gindex64(gindex ^ 1))
+			if err != nil {
+				t.Fatal(err)
+			}
+			proof[d] = sibling.MerkleRoot(hFn)
+			gindex >>= 1
+		}
+		blob := make(Blob, BlobSize(spec))
+		blob[0] = byte(i)
+		sidecars = append(sidecars, BlobSidecar{
+			Index:                       view.Uint64View(i),
+			Blob:                        blob,
+			KZGCommitment:               commitment,
+			KZGProof:                    KZGProof{0xaa},
+			SignedBlockHeader:           header,
+			KZGCommitmentInclusionProof: proof,
+		})
+	}
+	if err := sidecars.VerifyInclusionProofs(spec); err != nil {
+		t.Fatal(err)
+	}
+	root := sidecars.HashTreeRoot(spec, hFn)
+
+	data, err := json.Marshal(sidecars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON BlobSidecars
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	if fromJSON.HashTreeRoot(spec, hFn) != root {
+		t.Fatal("blob sidecars did not round-trip through JSON")
+	}
+
+	buf.Reset()
+	if err := sidecars.Serialize(spec, codec.NewEncodingWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	if uint64(buf.Len()) != sidecars.ByteLength(spec) {
+		t.Fatalf("unexpected byte length: %d, expected %d", sidecars.ByteLength(spec), buf.Len())
+	}
+	var fromSSZ BlobSidecars
+	if err := fromSSZ.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(buf.Bytes()), uint64(buf.Len()))); err != nil {
+		t.Fatal(err)
+	}
+	if fromSSZ.HashTreeRoot(spec, hFn) != root {
+		t.Fatal("blob sidecars did not round-trip through SSZ")
+	}
+
+	// a proof for a different index, or of a different commitment, is invalid
+	sidecars[1].Index = 2
+	if err := sidecars[1].VerifyInclusionProof(spec); err == nil {
+		t.Fatal("expected invalid inclusion proof for wrong index")
+	}
+	sidecars[2].KZGCommitment = common.KZGCommitment{0xff}
+	if err := sidecars[2].VerifyInclusionProof(spec); err == nil {
+		t.Fatal("expected invalid inclusion proof for wrong commitment")
+	}
+}
@@ -2,7 +2,10 @@ package eth2api
 
 import (
 	"context"
+	"encoding"
 	"fmt"
+	"io"
+	"net/url"
 )
 
 type Client interface {
@@ -12,6 +15,27 @@ type Client interface {
 // simplified Query interface. No duplicate key entries
 type Query map[string]interface{}
 
+// Values encodes the query params, values are either strings, fmt.Stringer or encoding.TextMarshaler.
+func (q Query) Values() (url.Values, error) {
+	out := make(url.Values, len(q))
+	for k, v := range q {
+		if s, ok := v.(string); ok {
+			out.Set(k, s)
+		} else if sv, ok := v.(fmt.Stringer); ok {
+			out.Set(k, sv.String())
+		} else if tm, ok := v.(encoding.TextMarshaler); ok {
+			tb, err := tm.MarshalText()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode query key %s: %w", k, err)
+			}
+			out.Set(k, string(tb))
+		} else {
+			return nil, fmt.Errorf("failed to encode query key '%s': unknown type", k)
+		}
+	}
+	return out, nil
+}
+
 type PreparedRequest interface {
 	// The type of request
 	Method() ReqMethod
@@ -26,6 +50,31 @@ type PreparedRequest interface {
 	Query() Query
 }
 
+// HeadersRequest is an optional PreparedRequest extension, for requests with additional headers.
+type HeadersRequest interface {
+	PreparedRequest
+	// Headers to add to the request, may be nil.
+	Headers() Headers
+}
+
+type headersReq struct {
+	PreparedRequest
+	headers Headers
+}
+
+func (r *headersReq) Headers() Headers {
+	return r.headers
+}
+
+func (r *headersReq) RetrySafe() bool {
+	return isRetrySafe(r.PreparedRequest)
+}
+
+// WithHeaders extends the request with additional headers.
+func WithHeaders(req PreparedRequest, headers Headers) HeadersRequest {
+	return &headersReq{PreparedRequest: req, headers: headers}
+}
+
 type Response interface {
 	// Decode into destination type. May throw a decoding error.
 	// Or throws DecodeNoContentErr if it was an error without returned value.
@@ -33,7 +82,38 @@ type Response interface {
 	// May only be called once.
 	Decode(dest interface{}) (code uint, err error)
 
-	// TODO: maybe expose headers?
+	// Headers of the response, may be nil.
+	// Header names are in canonical format, see http.CanonicalHeaderKey.
+	Headers() Headers
+}
+
+// StreamResponse is an optional Response extension, for responses that are consumed as a stream,
+// rather than decoded in full, like server-sent events.
+type StreamResponse interface {
+	Response
+	// Stream returns the response body, to be closed by the caller.
+	// Error responses are decoded, and returned as error instead.
+	Stream() (code uint, body io.ReadCloser, err error)
+}
+
+// HeadersPreset is implemented by decoding destinations that are preset with more response headers
+// than the Eth-Consensus-Version header, like ProducedBlock.
+type HeadersPreset interface {
+	PresetHeaders(headers Headers)
+}
+
+// PresetConsensusVersion presets the version of a ConsensusVersioned decoding destination,
+// to the version specified by the Eth-Consensus-Version header, if any.
+// A HeadersPreset destination is preset with all the headers instead.
+// Response implementations use this to decode versioned objects that do not specify the version in the body.
+func PresetConsensusVersion(headers Headers, dest interface{}) {
+	if v, ok := dest.(HeadersPreset); ok {
+		v.PresetHeaders(headers)
+	} else if v, ok := dest.(ConsensusVersioned); ok {
+		if version, ok := headers[ConsensusVersionHeader]; ok && version != "" {
+			v.PresetConsensusVersion(version)
+		}
+	}
 }
 
 type fullReq struct {
@@ -93,6 +173,10 @@ func BodyPOST(path string, body interface{}) PreparedRequest {
 	return &fullReq{method: POST, path: path, body: body, query: nil}
 }
 
+func QueryBodyPOST(query Query, path string, body interface{}) PreparedRequest {
+	return &fullReq{method: POST, path: path, body: body, query: query}
+}
+
 func SimpleRequest(ctx context.Context, cli Client, req PreparedRequest, dest interface{}) (exists bool, err error) {
 	resp := cli.Request(ctx, req)
 	code, err := resp.Decode(dest)
@@ -130,3 +214,17 @@ type ClientErr struct {
 func (ce ClientErr) Decode(dest interface{}) (uint, error) {
 	return 0, fmt.Errorf("client usage error, cannot decode: %w", ce.error)
 }
+
+func (ce ClientErr) Headers() Headers {
+	return nil
+}
+
+// TransportErr is a failure to execute a request, e.g. when the node cannot be reached.
+// Unlike other client errors, the same request may succeed at a later time or with a different node.
+type TransportErr struct {
+	error
+}
+
+func (te TransportErr) Unwrap() error {
+	return te.error
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/protolambda/eth2api"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/ztyp/view"
 )
 
 // Retrieves attestations included in requested block.
@@ -38,6 +39,18 @@ func PublishBlock(ctx context.Context, cli eth2api.Client, block eth2api.SignedB
 	return
 }
 
+// Like PublishBlock, but with the Eth-Consensus-Version header of the block,
+// and since deneb the blobs and KZG proofs of the block, see eth2api.Fork.SignedBlockContents.
+func PublishBlockContents(ctx context.Context, cli eth2api.Client, block *eth2api.VersionedSignedBlockContents) (valid bool, err error) {
+	req := eth2api.WithHeaders(eth2api.BodyPOST("/eth/v1/beacon/blocks", block.Data),
+		eth2api.Headers{eth2api.ConsensusVersionHeader: block.Version})
+	resp := cli.Request(ctx, req)
+	var code uint
+	code, err = resp.Decode(nil)
+	valid = code != 202
+	return
+}
+
 // Retrieves hashTreeRoot of BeaconBlock/BeaconBlockHeader.
 func BlockRoot(ctx context.Context, cli eth2api.Client, blockId eth2api.BlockId) (root common.Root, exists bool, err error) {
 	var dest eth2api.RootResponse
@@ -45,3 +58,49 @@ func BlockRoot(ctx context.Context, cli eth2api.Client, blockId eth2api.BlockId)
 	root = dest.Root
 	return
 }
+
+// Retrieves the blob sidecars of the given block, optionally filtered by blob indices.
+// Pre-deneb blocks have no blobs, and return an empty list.
+//
+// The inclusion proofs are not verified,
+// see eth2api.BlobSidecars.VerifyInclusionProofs to verify them against the body root of the block.
+func BlobSidecars(ctx context.Context, cli eth2api.Client, blockId eth2api.BlockId, indices []view.Uint64View, dest *eth2api.BlobSidecars) (exists bool, err error) {
+	var q eth2api.Query
+	if indices != nil {
+		q = eth2api.Query{"indices": eth2api.BlobIndexFilter(indices)}
+	}
+	return eth2api.SimpleRequest(ctx, cli, eth2api.FmtQueryGET(q, "/eth/v1/beacon/blob_sidecars/%s", blockId.BlockId()), eth2api.Wrap(dest))
+}
+
+// Instructs the beacon node to unblind the signed blinded block with the builder,
+// and to broadcast the full block to the beacon network.
+// A successful response (20X, i.e. no error returned) only indicates that the broadcast has been successful.
+// Blocks which fail the validation are still broadcast but a different status code is returned
+// (202, `valid` will be false)
+func PublishBlindedBlock(ctx context.Context, cli eth2api.Client, block *eth2api.VersionedSignedBlindedBeaconBlock) (valid bool, err error) {
+	req := eth2api.WithHeaders(eth2api.BodyPOST("/eth/v1/beacon/blinded_blocks", block.Data),
+		eth2api.Headers{eth2api.ConsensusVersionHeader: block.Version})
+	resp := cli.Request(ctx, req)
+	var code uint
+	code, err = resp.Decode(nil)
+	valid = code != 202
+	return
+}
+
+// Like PublishBlindedBlock, but the block is only broadcast if it passes the given broadcast validation.
+// Blocks which pass the broadcast validation, but fail further validation, are still broadcast
+// (202, `valid` will be false)
+func PublishBlindedBlockV2(ctx context.Context, cli eth2api.Client, block *eth2api.VersionedSignedBlindedBeaconBlock,
+	broadcastValidation eth2api.BroadcastValidation) (valid bool, err error) {
+	var q eth2api.Query
+	if broadcastValidation != "" {
+		q = eth2api.Query{"broadcast_validation": broadcastValidation}
+	}
+	req := eth2api.WithHeaders(eth2api.QueryBodyPOST(q, "/eth/v2/beacon/blinded_blocks", block.Data),
+		eth2api.Headers{eth2api.ConsensusVersionHeader: block.Version})
+	resp := cli.Request(ctx, req)
+	var code uint
+	code, err = resp.Decode(nil)
+	valid = code != 202
+	return
+}
@@ -10,7 +10,7 @@ import (
 )
 
 func TestBlockAttestations(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/blocks", "get_block_attestations",
+	shared_test.RunAllHTTP(t, "../tests/beacon/blocks", "get_block_attestations",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := BlockAttestations(ctx, cli, input.BlockId(), new([]phase0.Attestation))
 			return err
@@ -18,7 +18,7 @@ func TestBlockAttestations(t *testing.T) {
 }
 
 func TestBlock(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/blocks", "get_block",
+	shared_test.RunAllHTTP(t, "../tests/beacon/blocks", "get_block",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := Block(ctx, cli, input.BlockId(), new(phase0.SignedBeaconBlock))
 			return err
@@ -28,7 +28,7 @@ func TestBlock(t *testing.T) {
 // TODO TestBlockV2
 
 func TestBlockRoot(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/blocks", "get_block_root",
+	shared_test.RunAllHTTP(t, "../tests/beacon/blocks", "get_block_root",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, _, err := BlockRoot(ctx, cli, input.BlockId())
 			return err
@@ -39,7 +39,7 @@ func TestPublishBlock(t *testing.T) {
 	// TODO: test vectors here don't work yet.
 	t.SkipNow()
 
-	shared_test.RunAll(t, "../tests/beacon/blocks", "post_block",
+	shared_test.RunAllHTTP(t, "../tests/beacon/blocks", "post_block",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := PublishBlock(ctx, cli, input.Block)
 			return err
@@ -9,7 +9,7 @@ import (
 )
 
 func TestGenesis(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/genesis", "get_genesis",
+	shared_test.RunAllHTTP(t, "../tests/beacon/genesis", "get_genesis",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := Genesis(ctx, cli, new(eth2api.GenesisResponse))
 			return err
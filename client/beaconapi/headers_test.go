@@ -9,7 +9,7 @@ import (
 )
 
 func TestBlockHeader(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/headers", "get_header",
+	shared_test.RunAllHTTP(t, "../tests/beacon/headers", "get_header",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := BlockHeader(ctx, cli, input.BlockId(), new(eth2api.BeaconBlockHeaderAndInfo))
 			return err
@@ -17,7 +17,7 @@ func TestBlockHeader(t *testing.T) {
 }
 
 func TestBlockHeaders(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/headers", "get_headers",
+	shared_test.RunAllHTTP(t, "../tests/beacon/headers", "get_headers",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := BlockHeaders(ctx, cli, input.Slot, input.ParentRoot, new([]eth2api.BeaconBlockHeaderAndInfo))
 			return err
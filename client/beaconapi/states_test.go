@@ -10,7 +10,7 @@ import (
 )
 
 func TestEpochCommittees(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_committees",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_committees",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := EpochCommittees(ctx, cli,
 				input.StateId(), input.Epoch, input.CommitteeIndex, input.Slot, new([]eth2api.Committee))
@@ -21,7 +21,7 @@ func TestEpochCommittees(t *testing.T) {
 // TODO TestSyncCommittees
 
 func TestFinalityCheckpoints(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_finality_checkpoints",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_finality_checkpoints",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := FinalityCheckpoints(ctx, cli, input.StateId(), new(eth2api.FinalityCheckpoints))
 			return err
@@ -29,7 +29,7 @@ func TestFinalityCheckpoints(t *testing.T) {
 }
 
 func TestFork(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_fork",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_fork",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := Fork(ctx, cli, input.StateId(), new(common.Fork))
 			return err
@@ -37,7 +37,7 @@ func TestFork(t *testing.T) {
 }
 
 func TestStateRoot(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_state_root",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_state_root",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, _, err := StateRoot(ctx, cli, input.StateId())
 			return err
@@ -45,7 +45,7 @@ func TestStateRoot(t *testing.T) {
 }
 
 func TestStateValidator(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_validator",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_validator",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := StateValidator(ctx, cli, input.StateId(), input.ValidatorId(), new(eth2api.ValidatorResponse))
 			return err
@@ -53,7 +53,7 @@ func TestStateValidator(t *testing.T) {
 }
 
 func TestStateValidatorBalances(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_validator_balances",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_validator_balances",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := StateValidatorBalances(ctx, cli, input.StateId(), input.ValidatorIds(), new([]eth2api.ValidatorBalanceResponse))
 			return err
@@ -61,7 +61,7 @@ func TestStateValidatorBalances(t *testing.T) {
 }
 
 func TestStateValidators(t *testing.T) {
-	shared_test.RunAll(t, "../tests/beacon/states", "get_validators",
+	shared_test.RunAllHTTP(t, "../tests/beacon/states", "get_validators",
 		func(ctx context.Context, input *shared_test.Input, cli eth2api.Client) error {
 			_, err := StateValidators(ctx, cli, input.StateId(), input.ValidatorIds(), input.StatusFilter, new([]eth2api.ValidatorResponse))
 			return err
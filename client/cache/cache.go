@@ -0,0 +1,249 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+const (
+	DefaultMaxEntries = 1024
+	DefaultMaxBytes   = 64 << 20
+)
+
+// Stats are the cache statistics.
+type Stats struct {
+	// Hits are the requests that were served from the cache.
+	Hits uint64
+	// Misses are the cacheable requests that were not in the cache.
+	Misses uint64
+	// Bypassed are the requests for mutable resources, that are never cached.
+	Bypassed uint64
+	// Evictions are the entries that were removed to stay within the size limits.
+	Evictions uint64
+	// Uncacheable are the cacheable requests of which the response could not be cached,
+	// since the response of the underlying client is not an *eth2api.HttpResponse.
+	Uncacheable uint64
+	Entries     int
+	Bytes       int
+}
+
+type entry struct {
+	key    string
+	code   int
+	header http.Header
+	body   []byte
+	codec  eth2api.Codec
+}
+
+func (e *entry) size() int {
+	return len(e.key) + len(e.body)
+}
+
+// Client is an eth2api.Client decorator that caches the responses to requests for resources that never change,
+// in a LRU cache, limited in entries and bytes.
+//
+// Cached are the genesis, spec, fork schedule and deposit contract,
+// and the blocks and states (and sub-resources) that are requested by root, by genesis, or by a finalized slot.
+// Mutable aliases like head, justified and finalized pass through uncached.
+// Slots are only cached after SetFinalizedSlot is called.
+//
+// Only successful responses of the Eth2HttpClient are cached: the underlying client must return *eth2api.HttpResponse,
+// i.e. be an Eth2HttpClient, or a decorator that passes those through, like the record.Recorder.
+// Stacked on top of other clients, like the multinode clients, nothing is cached, and Stats reports the responses as uncacheable.
+//
+// The zero value is a cache with the default size limits, of which only the Client must be set.
+type Client struct {
+	Client eth2api.Client
+	// MaxEntries and MaxBytes limit the size of the cache, DefaultMaxEntries and DefaultMaxBytes if zero.
+	MaxEntries int
+	MaxBytes   int
+
+	mu           sync.Mutex
+	lru          *list.List
+	entries      map[string]*list.Element
+	bytes        int
+	finalized    common.Slot
+	hasFinalized bool
+	stats        Stats
+}
+
+var _ eth2api.Client = (*Client)(nil)
+
+func NewClient(cli eth2api.Client) *Client {
+	return &Client{
+		Client:     cli,
+		MaxEntries: DefaultMaxEntries,
+		MaxBytes:   DefaultMaxBytes,
+	}
+}
+
+// init allocates the cache on first use, c.mu must be held.
+func (c *Client) init() {
+	if c.lru != nil {
+		return
+	}
+	c.lru = list.New()
+	c.entries = make(map[string]*list.Element)
+	if c.MaxEntries == 0 {
+		c.MaxEntries = DefaultMaxEntries
+	}
+	if c.MaxBytes == 0 {
+		c.MaxBytes = DefaultMaxBytes
+	}
+}
+
+// SetFinalizedSlot updates the finalized slot, e.g. on finalized checkpoint events.
+// Requests by slot, at or before the finalized slot, are cached.
+func (c *Client) SetFinalizedSlot(slot common.Slot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.hasFinalized || slot > c.finalized {
+		c.finalized = slot
+		c.hasFinalized = true
+	}
+}
+
+// Stats returns the cache statistics.
+func (c *Client) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	out := c.stats
+	out.Entries = c.lru.Len()
+	out.Bytes = c.bytes
+	return out
+}
+
+// Purge removes all entries from the cache.
+func (c *Client) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+	c.bytes = 0
+}
+
+// cacheKey returns the key of the request, and if the request is cacheable.
+func (c *Client) cacheKey(req eth2api.PreparedRequest) (string, bool) {
+	if req.Method() != eth2api.GET {
+		return "", false
+	}
+	c.mu.Lock()
+	finalized, hasFinalized := c.finalized, c.hasFinalized
+	c.mu.Unlock()
+	if !immutable(req.Path(), finalized, hasFinalized) {
+		return "", false
+	}
+	key := req.Path()
+	if q := req.Query(); len(q) > 0 {
+		values, err := q.Values()
+		if err != nil {
+			return "", false
+		}
+		key += "?" + values.Encode()
+	}
+	return key, true
+}
+
+func (c *Client) get(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	if el, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(el)
+		c.stats.Hits++
+		return el.Value.(*entry), true
+	}
+	c.stats.Misses++
+	return nil, false
+}
+
+func (c *Client) add(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.init()
+	if e.size() > c.MaxBytes {
+		return
+	}
+	if el, ok := c.entries[e.key]; ok {
+		c.bytes -= el.Value.(*entry).size()
+		el.Value = e
+		c.lru.MoveToFront(el)
+	} else {
+		c.entries[e.key] = c.lru.PushFront(e)
+	}
+	c.bytes += e.size()
+	for c.lru.Len() > c.MaxEntries || c.bytes > c.MaxBytes {
+		oldest := c.lru.Back()
+		old := oldest.Value.(*entry)
+		c.lru.Remove(oldest)
+		delete(c.entries, old.key)
+		c.bytes -= old.size()
+		c.stats.Evictions++
+	}
+}
+
+func (c *Client) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	key, ok := c.cacheKey(req)
+	if !ok {
+		c.mu.Lock()
+		c.stats.Bypassed++
+		c.mu.Unlock()
+		return c.Client.Request(ctx, req)
+	}
+	if e, ok := c.get(key); ok {
+		return e.response()
+	}
+	resp := c.Client.Request(ctx, req)
+	hr, ok := resp.(*eth2api.HttpResponse)
+	if !ok {
+		c.mu.Lock()
+		c.stats.Uncacheable++
+		c.mu.Unlock()
+		return resp
+	}
+	if hr.StatusCode != 200 {
+		return resp
+	}
+	body, err := io.ReadAll(hr.Body)
+	_ = hr.Body.Close()
+	if err != nil {
+		return errResponse{fmt.Errorf("failed to read response body: %w", err)}
+	}
+	e := &entry{key: key, code: hr.StatusCode, header: hr.Header.Clone(), body: body, codec: hr.Codec}
+	c.add(e)
+	return e.response()
+}
+
+// response replays the cached response, it can be decoded like the original response.
+func (e *entry) response() *eth2api.HttpResponse {
+	return &eth2api.HttpResponse{
+		Response: &http.Response{
+			StatusCode: e.code,
+			Header:     e.header,
+			Body:       io.NopCloser(bytes.NewReader(e.body)),
+		},
+		Codec: e.codec,
+	}
+}
+
+type errResponse struct {
+	err error
+}
+
+func (r errResponse) Decode(dest interface{}) (uint, error) {
+	return 0, r.err
+}
+
+func (r errResponse) Headers() eth2api.Headers {
+	return nil
+}
@@ -0,0 +1,124 @@
+package cache
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+func TestImmutable(t *testing.T) {
+	cases := []struct {
+		path      string
+		immutable bool
+	}{
+		{"/eth/v1/beacon/genesis", true},
+		{"/eth/v1/config/spec", true},
+		{"/eth/v1/config/fork_schedule", true},
+		{"/eth/v1/node/syncing", false},
+		{"/eth/v2/beacon/blocks/0x4242424242424242424242424242424242424242424242424242424242424242", true},
+		{"/eth/v1/beacon/blocks/genesis/root", true},
+		{"/eth/v1/beacon/blocks/head/root", false},
+		{"/eth/v1/beacon/headers/justified", false},
+		{"/eth/v1/beacon/states/finalized/fork", false},
+		{"/eth/v1/beacon/states/100/validators", true},
+		{"/eth/v1/beacon/states/101/validators", false},
+		{"/eth/v2/debug/beacon/states/0x4242424242424242424242424242424242424242424242424242424242424242", true},
+		{"/eth/v2/validator/blocks/10", false},
+		{"/eth/v1/beacon/headers", false},
+	}
+	for _, c := range cases {
+		if got := immutable(c.path, 100, true); got != c.immutable {
+			t.Errorf("%s: expected immutable %v, got %v", c.path, c.immutable, got)
+		}
+	}
+	if immutable("/eth/v1/beacon/states/0/root", 0, false) {
+		t.Error("slots should not be immutable without finalized slot")
+	}
+}
+
+func TestCache(t *testing.T) {
+	var requests int32
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/blocks/:blockId/root", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		atomic.AddInt32(&requests, 1)
+		var root common.Root
+		copy(root[:], req.Param("blockId"))
+		return eth2api.RespondOK(eth2api.Wrap(&eth2api.RootResponse{Root: root}))
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := NewClient(&eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}})
+	cli.MaxEntries = 2
+	cli.SetFinalizedSlot(10)
+	ctx := context.Background()
+
+	blockRoot := func(id eth2api.BlockId) common.Root {
+		root, exists, err := beaconapi.BlockRoot(ctx, cli, id)
+		if err != nil || !exists {
+			t.Fatalf("failed to get block root of %s: exists %v, err: %v", id.BlockId(), exists, err)
+		}
+		return root
+	}
+	first := blockRoot(eth2api.BlockIdSlot(5))
+	if second := blockRoot(eth2api.BlockIdSlot(5)); first != second {
+		t.Fatal("cached response differs")
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 request, got %d", requests)
+	}
+	blockRoot(eth2api.BlockHead)
+	blockRoot(eth2api.BlockHead)
+	blockRoot(eth2api.BlockIdSlot(20))
+	if requests != 4 {
+		t.Fatalf("expected mutable requests to pass through, got %d requests", requests)
+	}
+	blockRoot(eth2api.BlockIdSlot(6))
+	blockRoot(eth2api.BlockIdSlot(7))
+	stats := cli.Stats()
+	if stats.Hits != 1 || stats.Misses != 3 || stats.Bypassed != 3 || stats.Evictions != 1 || stats.Entries != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+// opaqueClient hides the type of the responses, like decorators that do not pass through the *eth2api.HttpResponse.
+type opaqueClient struct {
+	eth2api.Client
+}
+
+func (c opaqueClient) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	return struct{ eth2api.Response }{c.Client.Request(ctx, req)}
+}
+
+func TestCacheZeroValue(t *testing.T) {
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/genesis", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		return eth2api.RespondOK(eth2api.Wrap(&eth2api.GenesisResponse{GenesisTime: 42}))
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	httpCli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	for _, c := range []struct {
+		cli               *Client
+		hits, uncacheable uint64
+	}{
+		{&Client{Client: httpCli}, 1, 0},
+		{&Client{Client: opaqueClient{httpCli}}, 0, 2},
+	} {
+		for i := 0; i < 2; i++ {
+			var genesis eth2api.GenesisResponse
+			if exists, err := beaconapi.Genesis(ctx, c.cli, &genesis); err != nil || !exists || genesis.GenesisTime != 42 {
+				t.Fatalf("unexpected genesis: %v, exists %v, err: %v", genesis, exists, err)
+			}
+		}
+		if stats := c.cli.Stats(); stats.Hits != c.hits || stats.Uncacheable != c.uncacheable {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	}
+}
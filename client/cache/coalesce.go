@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+)
+
+type call struct {
+	done    chan struct{}
+	cancel  context.CancelFunc
+	waiters int
+
+	// result, set before done is closed: either a shared entry, a shared error,
+	// or a response that is not shareable and can only be claimed by one caller.
+	entry   *entry
+	err     error
+	resp    eth2api.Response
+	claimed bool
+}
+
+// Coalescer is an eth2api.Client decorator that merges concurrent identical GET requests,
+// with the same path and query, into a single upstream request.
+// The response body is shared between the callers, and decoded by each caller independently.
+//
+// The upstream request is only cancelled when all callers have cancelled their context.
+// Requests with extra headers, like event streams, are not coalesced.
+// Only responses of the Eth2HttpClient can be shared, for other responses each caller sends their own request.
+type Coalescer struct {
+	Client eth2api.Client
+
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+var _ eth2api.Client = (*Coalescer)(nil)
+
+func NewCoalescer(cli eth2api.Client) *Coalescer {
+	return &Coalescer{Client: cli, calls: make(map[string]*call)}
+}
+
+func coalesceKey(req eth2api.PreparedRequest) (string, bool) {
+	if req.Method() != eth2api.GET {
+		return "", false
+	}
+	if hr, ok := req.(eth2api.HeadersRequest); ok && len(hr.Headers()) > 0 {
+		return "", false
+	}
+	key := string(req.Method()) + " " + req.Path()
+	if q := req.Query(); len(q) > 0 {
+		values, err := q.Values()
+		if err != nil {
+			return "", false
+		}
+		key += "?" + values.Encode()
+	}
+	return key, true
+}
+
+func (c *Coalescer) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	key, ok := coalesceKey(req)
+	if !ok {
+		return c.Client.Request(ctx, req)
+	}
+	c.mu.Lock()
+	cl, ok := c.calls[key]
+	if !ok {
+		// The upstream request outlives the caller that started it, if others are still waiting for it.
+		upstreamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		cl = &call{done: make(chan struct{}), cancel: cancel}
+		c.calls[key] = cl
+		go c.run(upstreamCtx, key, cl, req)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+	case <-ctx.Done():
+		c.mu.Lock()
+		cl.waiters--
+		if cl.waiters == 0 {
+			cl.cancel()
+			if c.calls[key] == cl {
+				delete(c.calls, key)
+			}
+		}
+		c.mu.Unlock()
+		return errResponse{ctx.Err()}
+	}
+	if cl.entry != nil {
+		return cl.entry.response()
+	}
+	if cl.err != nil {
+		return errResponse{cl.err}
+	}
+	c.mu.Lock()
+	claimed := cl.claimed
+	cl.claimed = true
+	c.mu.Unlock()
+	if !claimed {
+		return cl.resp
+	}
+	return c.Client.Request(ctx, req)
+}
+
+func (c *Coalescer) run(ctx context.Context, key string, cl *call, req eth2api.PreparedRequest) {
+	resp := c.Client.Request(ctx, req)
+	if hr, ok := resp.(*eth2api.HttpResponse); ok {
+		body, err := io.ReadAll(hr.Body)
+		_ = hr.Body.Close()
+		cl.cancel()
+		if err != nil {
+			cl.err = fmt.Errorf("failed to read response body: %w", err)
+		} else {
+			cl.entry = &entry{key: key, code: hr.StatusCode, header: hr.Header.Clone(), body: body, codec: hr.Codec}
+		}
+	} else {
+		// the response may still use the context when it is decoded
+		cl.resp = resp
+	}
+	c.mu.Lock()
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+	c.mu.Unlock()
+	close(cl.done)
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/validatorapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+func newDutiesServer(t *testing.T, requests *int32, release <-chan struct{}) *eth2api.Eth2HttpClient {
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/duties/proposer/:epoch", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		atomic.AddInt32(requests, 1)
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return eth2api.RespondOK(&eth2api.DependentProposerDuty{
+			DependentRoot: common.Root{1},
+			Data:          []eth2api.ProposerDuty{{ValidatorIndex: 42, Slot: 100}},
+		})
+	}))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+}
+
+func TestCoalescer(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	cli := NewCoalescer(newDutiesServer(t, &requests, release))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var duties eth2api.DependentProposerDuty
+			if _, err := validatorapi.ProposerDuties(context.Background(), cli, 3, &duties); err != nil {
+				errs <- err
+				return
+			}
+			if len(duties.Data) != 1 || duties.Data[0].ValidatorIndex != 42 {
+				errs <- fmt.Errorf("unexpected duties: %v", duties.Data)
+			}
+		}()
+	}
+	// wait for the callers to join the upstream request
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("unexpected result: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", requests)
+	}
+}
+
+func TestCoalescerCancel(t *testing.T) {
+	var requests int32
+	release := make(chan struct{})
+	cli := NewCoalescer(newDutiesServer(t, &requests, release))
+
+	// the caller that started the request cancels, the other caller still gets the response.
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		var duties eth2api.DependentProposerDuty
+		_, err := validatorapi.ProposerDuties(ctx, cli, 3, &duties)
+		first <- err
+	}()
+	for atomic.LoadInt32(&requests) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error, 1)
+	go func() {
+		var duties eth2api.DependentProposerDuty
+		_, err := validatorapi.ProposerDuties(context.Background(), cli, 3, &duties)
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-first; err == nil {
+		t.Fatal("expected cancelled caller to fail")
+	}
+	close(release)
+	if err := <-second; err != nil {
+		t.Fatalf("expected other caller to succeed: %v", err)
+	}
+	if requests != 1 {
+		t.Fatalf("expected 1 upstream request, got %d", requests)
+	}
+}
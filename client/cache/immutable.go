@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// immutablePaths are the paths of resources that never change.
+var immutablePaths = map[string]bool{
+	"/eth/v1/beacon/genesis":          true,
+	"/eth/v1/config/spec":             true,
+	"/eth/v1/config/fork_schedule":    true,
+	"/eth/v1/config/deposit_contract": true,
+}
+
+// idSegments are the path segments that are followed by a block or state identifier.
+var idSegments = map[string]bool{
+	"blocks":         true,
+	"blinded_blocks": true,
+	"headers":        true,
+	"states":         true,
+	"blob_sidecars":  true,
+}
+
+// idImmutable checks if the block or state identifier always refers to the same block or state:
+// a root, the genesis, or a slot at or before the finalized slot.
+// Other aliases, like head, justified and finalized, are mutable.
+func idImmutable(id string, finalized common.Slot, hasFinalized bool) bool {
+	if strings.HasPrefix(id, "0x") {
+		return true
+	}
+	if id == "genesis" {
+		return true
+	}
+	slot, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return false
+	}
+	return hasFinalized && common.Slot(slot) <= finalized
+}
+
+// immutable checks if the resource at the given path never changes.
+// Paths are immutable if they are known to be fixed, or if they refer to a block or state with an immutable identifier.
+func immutable(path string, finalized common.Slot, hasFinalized bool) bool {
+	if immutablePaths[path] {
+		return true
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) < 2 || segments[0] != "eth" {
+		return false
+	}
+	for i := 2; i+1 < len(segments); i++ {
+		if idSegments[segments[i]] {
+			// e.g. /eth/v1/beacon/headers/{block_id}, but not the /eth/v1/validator/blocks/{slot} block production.
+			if segments[i-1] != "beacon" && segments[i-1] != "rewards" {
+				return false
+			}
+			return idImmutable(segments[i+1], finalized, hasFinalized)
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package eventsapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+// Event, as received from the event stream.
+type Event struct {
+	// ID of the event, may be empty if the node does not identify events.
+	ID    string
+	Topic eth2api.EventTopic
+	// Data is the decoded event:
+	//   - *eth2api.HeadEvent for eth2api.EventTopicHead
+	//   - *eth2api.BlockEvent for eth2api.EventTopicBlock
+	//   - *phase0.Attestation for eth2api.EventTopicAttestation
+	//   - *phase0.SignedVoluntaryExit for eth2api.EventTopicVoluntaryExit
+	//   - *eth2api.FinalizedCheckpointEvent for eth2api.EventTopicFinalizedCheckpoint
+	//   - *eth2api.ChainReorgEvent for eth2api.EventTopicChainReorg
+	//   - *altair.SignedContributionAndProof for eth2api.EventTopicContributionAndProof
+	//   - *eth2api.PayloadAttributesEvent for eth2api.EventTopicPayloadAttributes
+	//   - *eth2api.BlobSidecarEvent for eth2api.EventTopicBlobSidecar
+	//   - json.RawMessage for unknown topics
+	Data interface{}
+}
+
+// DecodeEvent decodes the JSON data of an event of the given topic.
+// Data of unknown topics is returned as json.RawMessage.
+func DecodeEvent(topic eth2api.EventTopic, data []byte) (interface{}, error) {
+	var dest interface{}
+	switch topic {
+	case eth2api.EventTopicHead:
+		dest = new(eth2api.HeadEvent)
+	case eth2api.EventTopicBlock:
+		dest = new(eth2api.BlockEvent)
+	case eth2api.EventTopicAttestation:
+		dest = new(phase0.Attestation)
+	case eth2api.EventTopicVoluntaryExit:
+		dest = new(phase0.SignedVoluntaryExit)
+	case eth2api.EventTopicFinalizedCheckpoint:
+		dest = new(eth2api.FinalizedCheckpointEvent)
+	case eth2api.EventTopicChainReorg:
+		dest = new(eth2api.ChainReorgEvent)
+	case eth2api.EventTopicContributionAndProof:
+		dest = new(altair.SignedContributionAndProof)
+	case eth2api.EventTopicPayloadAttributes:
+		dest = new(eth2api.PayloadAttributesEvent)
+	case eth2api.EventTopicBlobSidecar:
+		dest = new(eth2api.BlobSidecarEvent)
+	default:
+		return json.RawMessage(data), nil
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return nil, fmt.Errorf("failed to decode %q event: %w", topic, err)
+	}
+	return dest, nil
+}
@@ -0,0 +1,67 @@
+package eventsapi
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A server-sent event frame, see https://html.spec.whatwg.org/multipage/server-sent-events.html
+type sseFrame struct {
+	event   string
+	data    string
+	hasData bool
+	id      string
+	hasID   bool
+	retry   time.Duration
+}
+
+// readFrame reads the next frame from the stream.
+// Frames without data are returned too, but should not be dispatched,
+// they may still update the last event ID and the reconnection delay.
+func readFrame(r *bufio.Reader) (f sseFrame, err error) {
+	var data strings.Builder
+	empty := true
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			// incomplete frames are discarded
+			return sseFrame{}, err
+		}
+		line = strings.TrimSuffix(line, "\n")
+		line = strings.TrimSuffix(line, "\r")
+		if line == "" {
+			if empty {
+				continue
+			}
+			f.data = data.String()
+			return f, nil
+		}
+		if line[0] == ':' { // comment, e.g. a keep-alive
+			continue
+		}
+		empty = false
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			f.event = value
+		case "data":
+			if f.hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			f.hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				f.id = value
+				f.hasID = true
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(value, 10, 32); err == nil {
+				f.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+}
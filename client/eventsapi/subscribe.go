@@ -0,0 +1,133 @@
+package eventsapi
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+// DefaultReconnectDelay is the delay before reconnecting to an interrupted event stream,
+// if the Subscriber does not specify one, and the node does not advise one.
+const DefaultReconnectDelay = 3 * time.Second
+
+// Subscriber subscribes to the event stream of a node,
+// and automatically reconnects to resume the stream when it is interrupted.
+//
+// Note that request timeouts of the client, like http.Client.Timeout, also apply to the stream.
+type Subscriber struct {
+	Client eth2api.Client
+	Topics []eth2api.EventTopic
+
+	// Called for every received event, from the goroutine that runs the subscriber.
+	OnEvent func(ev *Event)
+	// Optional, called with the error when the stream is interrupted, or when an event cannot be decoded.
+	OnError func(err error)
+
+	// Delay before reconnecting after the stream is interrupted.
+	// The retry delay advised by the node has priority. Defaults to DefaultReconnectDelay.
+	ReconnectDelay time.Duration
+	// ID of the last received event, used to resume the stream after reconnecting.
+	// May be preset to resume a previous subscription.
+	LastEventID string
+}
+
+// Run opens the event stream, and delivers the events, until the context is canceled,
+// or until the node rejects the subscription with a 4xx error (e.g. for unknown topics).
+// The context error is returned after cancellation.
+func (s *Subscriber) Run(ctx context.Context) error {
+	delay := s.ReconnectDelay
+	if delay == 0 {
+		delay = DefaultReconnectDelay
+	}
+	for {
+		retry, err := s.stream(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var apiErr eth2api.ApiError
+		if errors.As(err, &apiErr) && apiErr.Code() >= 400 && apiErr.Code() < 500 {
+			return err
+		}
+		if s.OnError != nil {
+			s.OnError(err)
+		}
+		if retry > 0 {
+			delay = retry
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// stream reads the events of a single connection, until it is interrupted.
+// It returns the reconnection delay advised by the node, if any.
+func (s *Subscriber) stream(ctx context.Context) (retry time.Duration, err error) {
+	headers := eth2api.Headers{"Accept": "text/event-stream"}
+	if s.LastEventID != "" {
+		headers["Last-Event-ID"] = s.LastEventID
+	}
+	req := eth2api.WithHeaders(eth2api.QueryGET(eth2api.Query{"topics": eth2api.EventTopicFilter(s.Topics)}, "/eth/v1/events"), headers)
+	resp := s.Client.Request(ctx, req)
+	streamResp, ok := resp.(eth2api.StreamResponse)
+	if !ok {
+		if _, err := resp.Decode(nil); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("client response type %T does not support streaming", resp)
+	}
+	_, body, err := streamResp.Stream()
+	if err != nil {
+		return 0, err
+	}
+	defer body.Close()
+	r := bufio.NewReader(body)
+	for {
+		f, err := readFrame(r)
+		if err != nil {
+			return retry, fmt.Errorf("event stream interrupted: %w", err)
+		}
+		if f.retry > 0 {
+			retry = f.retry
+		}
+		if f.hasID {
+			s.LastEventID = f.id
+		}
+		if !f.hasData {
+			continue
+		}
+		topic := eth2api.EventTopic(f.event)
+		data, err := DecodeEvent(topic, []byte(f.data))
+		if err != nil {
+			if s.OnError != nil {
+				s.OnError(err)
+			}
+			continue
+		}
+		s.OnEvent(&Event{ID: f.id, Topic: topic, Data: data})
+	}
+}
+
+// Subscribe to the event stream of the given topics, and call onEvent for every event.
+// Interrupted streams are resumed automatically. See Subscriber.Run for details.
+func Subscribe(ctx context.Context, cli eth2api.Client, topics []eth2api.EventTopic, onEvent func(ev *Event)) error {
+	s := &Subscriber{Client: cli, Topics: topics, OnEvent: onEvent}
+	return s.Run(ctx)
+}
+
+// Events subscribes to the event stream of the given topics, and sends every event to the channel.
+// The channel is not closed when the subscription ends. See Subscriber.Run for details.
+func Events(ctx context.Context, cli eth2api.Client, topics []eth2api.EventTopic, dest chan<- *Event) error {
+	return Subscribe(ctx, cli, topics, func(ev *Event) {
+		select {
+		case dest <- ev:
+		case <-ctx.Done():
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package eventsapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+func TestSubscriber(t *testing.T) {
+	connections := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if topics := r.URL.Query().Get("topics"); topics != "head,attestation" {
+			w.WriteHeader(400)
+			_, _ = fmt.Fprintf(w, `{"code": 400, "message": "unexpected topics: %s"}`, topics)
+			return
+		}
+		connections++
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(200)
+		switch connections {
+		case 1:
+			_, _ = fmt.Fprint(w, "retry: 10\n\n: keep-alive\n\n")
+			_, _ = fmt.Fprint(w, "event: head\nid: 1\ndata: {\"slot\": \"10\", \"epoch_transition\": true}\n\n")
+			_, _ = fmt.Fprint(w, "event: attestation\nid: 2\ndata: {\"aggregation_bits\": \"0x01\",\ndata: \"data\": {\"slot\": \"9\"}}\n\n")
+			_, _ = fmt.Fprint(w, "event: head\nid: 3\ndata: {\"slot\": ") // interrupted
+		case 2:
+			if id := r.Header.Get("Last-Event-ID"); id != "2" {
+				t.Errorf("unexpected Last-Event-ID: %q", id)
+			}
+			_, _ = fmt.Fprint(w, "event: head\nid: 3\ndata: {\"slot\": \"11\"}\n\n")
+		}
+	}))
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var events []*Event
+	sub := &Subscriber{
+		Client: cli,
+		Topics: []eth2api.EventTopic{eth2api.EventTopicHead, eth2api.EventTopicAttestation},
+		OnEvent: func(ev *Event) {
+			events = append(events, ev)
+			if len(events) == 3 {
+				cancel()
+			}
+		},
+		ReconnectDelay: time.Minute, // overridden by the retry advised by the server
+	}
+	if err := sub.Run(ctx); err != context.Canceled {
+		t.Fatalf("unexpected subscription end: %v", err)
+	}
+	if connections != 2 {
+		t.Fatalf("expected a reconnect, got %d connections", connections)
+	}
+	if head, ok := events[0].Data.(*eth2api.HeadEvent); !ok || head.Slot != 10 || !head.EpochTransition {
+		t.Fatalf("unexpected first event: %v", events[0].Data)
+	}
+	if att, ok := events[1].Data.(*phase0.Attestation); !ok || att.Data.Slot != 9 {
+		t.Fatalf("unexpected second event: %v", events[1].Data)
+	}
+	if head, ok := events[2].Data.(*eth2api.HeadEvent); !ok || head.Slot != 11 || events[2].ID != "3" {
+		t.Fatalf("unexpected third event: %v", events[2].Data)
+	}
+}
+
+func TestSubscriberRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		_, _ = fmt.Fprint(w, `{"code": 400, "message": "unknown topic"}`)
+	}))
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	err := Subscribe(context.Background(), cli, []eth2api.EventTopic{"foo"}, func(ev *Event) {})
+	if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 400 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
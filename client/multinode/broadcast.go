@@ -0,0 +1,195 @@
+package multinode
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Outcome is the result of a broadcast request to a single node.
+type Outcome struct {
+	// Index of the node in Broadcast.Nodes
+	Index int
+	Code  uint
+	Err   error
+}
+
+// Broadcast is an eth2api.Client that sends POST requests, like publishing blocks and attestations,
+// to all nodes in parallel. Other requests are read requests, and go to a single node.
+//
+// Decoding the response of a broadcast returns as soon as one of the nodes succeeds,
+// the requests to the other nodes continue in the background, detached from the request context.
+// If all nodes fail, the error is an *eth2api.ErrorMessage, with the failure of each node indexed by node.
+type Broadcast struct {
+	Nodes []eth2api.Client
+	// Timeout of the broadcast requests to the nodes, DefaultBroadcastTimeout if zero.
+	// The broadcast requests are not cancelled with the request context, only by this timeout.
+	Timeout time.Duration
+	// Reads is the client to send read requests to, the first node if nil.
+	// E.g. a failover Client over the same nodes.
+	Reads eth2api.Client
+	// OnOutcome is called with the outcome of each node, e.g. for logging, optional.
+	// Outcomes may be reported after the response is decoded.
+	OnOutcome func(out Outcome)
+}
+
+// DefaultBroadcastTimeout is the Broadcast timeout of the requests to the nodes, if none is configured.
+const DefaultBroadcastTimeout = 30 * time.Second
+
+var _ eth2api.Client = (*Broadcast)(nil)
+
+func (b *Broadcast) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	if req.Method() != eth2api.POST {
+		if b.Reads != nil {
+			return b.Reads.Request(ctx, req)
+		}
+		if len(b.Nodes) == 0 {
+			return noNodesResponse{}
+		}
+		return b.Nodes[0].Request(ctx, req)
+	}
+	return &BroadcastResponse{b: b, ctx: ctx, req: req, done: make(chan struct{})}
+}
+
+type noNodesResponse struct{}
+
+func (noNodesResponse) Decode(dest interface{}) (uint, error) {
+	return 0, fmt.Errorf("no nodes to request")
+}
+
+func (noNodesResponse) Headers() eth2api.Headers {
+	return nil
+}
+
+// BroadcastResponse is the response to a broadcast POST request.
+type BroadcastResponse struct {
+	b   *Broadcast
+	ctx context.Context
+	req eth2api.PreparedRequest
+
+	mu       sync.Mutex
+	started  bool
+	headers  eth2api.Headers
+	outcomes []Outcome
+	done     chan struct{}
+}
+
+var _ eth2api.Response = (*BroadcastResponse)(nil)
+
+// Decode sends the request to all nodes, and decodes the response of the first node that succeeds.
+// If the request context is done before any node succeeds, the context error is returned,
+// and the broadcast continues in the background.
+func (r *BroadcastResponse) Decode(dest interface{}) (code uint, err error) {
+	r.mu.Lock()
+	r.started = true
+	r.mu.Unlock()
+	nodes := r.b.Nodes
+	if len(nodes) == 0 {
+		close(r.done)
+		return 0, fmt.Errorf("no nodes to request")
+	}
+	timeout := r.b.Timeout
+	if timeout == 0 {
+		timeout = DefaultBroadcastTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(r.ctx), timeout)
+	// Holding the dest token is required to decode into the destination, one node at a time.
+	// Once won is true, the destination is not written to anymore.
+	destToken := make(chan struct{}, 1)
+	won := false
+	results := make(chan Outcome, len(nodes))
+	var wg sync.WaitGroup
+	for i, n := range nodes {
+		wg.Add(1)
+		go func(i int, n eth2api.Client) {
+			defer wg.Done()
+			resp := n.Request(ctx, r.req)
+			// Only the first success is decoded into the destination, the others are just closed.
+			out := Outcome{Index: i}
+			destToken <- struct{}{}
+			if won {
+				<-destToken
+				out.Code, out.Err = resp.Decode(nil)
+			} else {
+				out.Code, out.Err = resp.Decode(dest)
+				if out.Err == nil {
+					won = true
+					r.mu.Lock()
+					r.headers = resp.Headers()
+					r.mu.Unlock()
+				}
+				<-destToken
+			}
+			r.mu.Lock()
+			r.outcomes = append(r.outcomes, out)
+			r.mu.Unlock()
+			if r.b.OnOutcome != nil {
+				r.b.OnOutcome(out)
+			}
+			results <- out
+		}(i, n)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+		close(r.done)
+	}()
+	failures := make([]eth2api.IndexedErrorMessageItem, 0, len(nodes))
+	ctxDone := r.ctx.Done()
+	for range nodes {
+		var out Outcome
+		select {
+		case out = <-results:
+		case <-ctxDone:
+			// Stop decoding into the destination, it is no longer ours after returning.
+			destToken <- struct{}{}
+			succeeded := won
+			won = true
+			<-destToken
+			if !succeeded {
+				return 0, r.ctx.Err()
+			}
+			// a node just succeeded, its outcome is on the way
+			ctxDone = nil
+			out = <-results
+		}
+		if out.Err == nil {
+			return out.Code, nil
+		}
+		// the lowest status code is the most specific, e.g. a 400 for an invalid block over a 503 of a syncing node.
+		if code == 0 || (out.Code != 0 && out.Code < code) {
+			code = out.Code
+		}
+		failures = append(failures, eth2api.IndexedErrorMessageItem{Index: uint(out.Index), Message: out.Err.Error()})
+	}
+	return code, &eth2api.ErrorMessage{
+		CodeValue: code,
+		Message:   fmt.Sprintf("broadcast failed on all %d nodes", len(nodes)),
+		Failures:  failures,
+	}
+}
+
+// Headers of the response of the first node that succeeded, nil if none succeeded (yet).
+func (r *BroadcastResponse) Headers() eth2api.Headers {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers
+}
+
+// Outcomes waits for all nodes to respond, and returns their outcomes, in order of completion.
+// Nil is returned if Decode was not called, since the request is only sent by Decode.
+func (r *BroadcastResponse) Outcomes() []Outcome {
+	r.mu.Lock()
+	started := r.started
+	r.mu.Unlock()
+	if !started {
+		return nil
+	}
+	<-r.done
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Outcome(nil), r.outcomes...)
+}
@@ -0,0 +1,158 @@
+package multinode
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+)
+
+func newPublishNode(t *testing.T, code uint, published *int32) eth2api.Client {
+	return newBlockingPublishNode(t, code, published, nil)
+}
+
+// newBlockingPublishNode creates a node that does not respond until release is closed, if not nil.
+func newBlockingPublishNode(t *testing.T, code uint, published *int32, release chan struct{}) eth2api.Client {
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.POST, "/publish", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		if release != nil {
+			<-release
+		}
+		var body string
+		if err := req.DecodeBody(&body); err != nil {
+			return eth2api.RespondBadInput(err)
+		}
+		if code != 200 {
+			return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: code, Message: "rejected " + body})
+		}
+		atomic.AddInt32(published, 1)
+		return eth2api.RespondOKMsg("published " + body)
+	}))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+}
+
+func TestBroadcast(t *testing.T) {
+	var published int32
+	b := &Broadcast{Nodes: []eth2api.Client{
+		newPublishNode(t, 503, &published),
+		newPublishNode(t, 200, &published),
+		newPublishNode(t, 200, &published),
+	}}
+	resp := b.Request(context.Background(), eth2api.BodyPOST("/publish", "block"))
+	var msg eth2api.ErrorMessage
+	if code, err := resp.Decode(&msg); err != nil || code != 200 {
+		t.Fatalf("expected success, got code %d, err: %v", code, err)
+	}
+	if msg.Message != "published block" {
+		t.Fatalf("unexpected response: %q", msg.Message)
+	}
+	outcomes := resp.(*BroadcastResponse).Outcomes()
+	if len(outcomes) != 3 {
+		t.Fatalf("expected 3 outcomes, got %d", len(outcomes))
+	}
+	for _, out := range outcomes {
+		if (out.Index == 0) != (out.Err != nil) {
+			t.Fatalf("unexpected outcome of node %d: code %d, err: %v", out.Index, out.Code, out.Err)
+		}
+	}
+	if atomic.LoadInt32(&published) != 2 {
+		t.Fatalf("expected 2 nodes to publish, got %d", published)
+	}
+}
+
+func TestBroadcastAllFail(t *testing.T) {
+	var published int32
+	var reported int32
+	b := &Broadcast{
+		Nodes: []eth2api.Client{
+			newPublishNode(t, 503, &published),
+			newPublishNode(t, 400, &published),
+		},
+		OnOutcome: func(out Outcome) {
+			atomic.AddInt32(&reported, 1)
+		},
+	}
+	code, err := b.Request(context.Background(), eth2api.BodyPOST("/publish", "block")).Decode(nil)
+	if code != 400 {
+		t.Fatalf("expected the most specific error code, got %d", code)
+	}
+	errMsg, ok := err.(*eth2api.ErrorMessage)
+	if !ok {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(errMsg.Failures) != 2 {
+		t.Fatalf("expected a failure per node, got %v", errMsg.Failures)
+	}
+	for _, f := range errMsg.Failures {
+		if !strings.HasSuffix(f.Message, "rejected block") {
+			t.Fatalf("unexpected failure of node %d: %q", f.Index, f.Message)
+		}
+	}
+	if atomic.LoadInt32(&reported) != 2 {
+		t.Fatal("expected outcome of each node to be reported")
+	}
+}
+
+func TestBroadcastReads(t *testing.T) {
+	a, b := newTestNode(t, "a"), newTestNode(t, "b")
+	cli := &Broadcast{Nodes: []eth2api.Client{a.cli, b.cli}}
+	if v, err := version(t, cli); err != nil || v != "a" {
+		t.Fatalf("expected read from first node, got %q, err: %v", v, err)
+	}
+	if atomic.LoadInt32(&b.requests) != 0 {
+		t.Fatal("read request was broadcast")
+	}
+}
+
+func TestBroadcastDetached(t *testing.T) {
+	var published int32
+	release := make(chan struct{})
+	b := &Broadcast{Nodes: []eth2api.Client{
+		newPublishNode(t, 200, &published),
+		newBlockingPublishNode(t, 200, &published, release),
+	}}
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := b.Request(ctx, eth2api.BodyPOST("/publish", "block"))
+	if code, err := resp.Decode(nil); err != nil || code != 200 {
+		t.Fatalf("expected success, got code %d, err: %v", code, err)
+	}
+	// the caller is done after the first success, the slow node still gets the block
+	cancel()
+	close(release)
+	outcomes := resp.(*BroadcastResponse).Outcomes()
+	if len(outcomes) != 2 || outcomes[1].Err != nil {
+		t.Fatalf("expected both nodes to succeed, got %v", outcomes)
+	}
+	if atomic.LoadInt32(&published) != 2 {
+		t.Fatalf("expected 2 nodes to publish, got %d", published)
+	}
+}
+
+func TestBroadcastCancelled(t *testing.T) {
+	var published int32
+	release := make(chan struct{})
+	b := &Broadcast{Nodes: []eth2api.Client{newBlockingPublishNode(t, 200, &published, release)}}
+	ctx, cancel := context.WithCancel(context.Background())
+	resp := b.Request(ctx, eth2api.BodyPOST("/publish", "block"))
+	if outcomes := resp.(*BroadcastResponse).Outcomes(); outcomes != nil {
+		t.Fatalf("expected no outcomes before decoding, got %v", outcomes)
+	}
+	cancel()
+	var msg eth2api.ErrorMessage
+	if _, err := resp.Decode(&msg); err != context.Canceled {
+		t.Fatalf("expected context error, got %v", err)
+	}
+	close(release)
+	outcomes := resp.(*BroadcastResponse).Outcomes()
+	if len(outcomes) != 1 || outcomes[0].Err != nil {
+		t.Fatalf("expected the broadcast to continue, got %v", outcomes)
+	}
+	if msg.Message != "" {
+		t.Fatalf("response was decoded after the request was cancelled: %q", msg.Message)
+	}
+}
@@ -0,0 +1,249 @@
+package multinode
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/nodeapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// Policy decides the order in which the nodes are tried.
+type Policy uint8
+
+const (
+	// PrimaryFallback tries the nodes in order, the first node is the primary.
+	PrimaryFallback Policy = iota
+	// RoundRobin spreads the requests over the nodes, by rotating the order for every request.
+	RoundRobin
+	// HealthAware prefers the nodes with the smallest sync distance, as probed in the background.
+	HealthAware
+)
+
+const DefaultProbeInterval = 12 * time.Second
+
+type node struct {
+	cli eth2api.Client
+
+	mu           sync.Mutex
+	ejected      bool
+	syncDistance common.Slot
+}
+
+// Client is an eth2api.Client that composes several nodes, and fails over to the next node when a request fails.
+//
+// Transport errors and 5xx responses (incl. 503 when syncing) are retried on the next node,
+// and eject the node that failed. Other responses, like 4xx errors, are returned to the caller.
+// Ejected nodes are only tried as last resort, until a background probe finds them healthy again, see Run.
+type Client struct {
+	policy Policy
+	nodes  []*node
+	next   uint32
+
+	// ProbeInterval is the interval between probes of the nodes, DefaultProbeInterval if zero.
+	ProbeInterval time.Duration
+	// OnEject is called when a node is ejected, with the index of the node, optional.
+	OnEject func(index int, err error)
+}
+
+var _ eth2api.Client = (*Client)(nil)
+
+func NewClient(policy Policy, nodes ...eth2api.Client) *Client {
+	c := &Client{policy: policy}
+	for _, cli := range nodes {
+		c.nodes = append(c.nodes, &node{cli: cli})
+	}
+	return c
+}
+
+// Ejected returns the indices of the nodes that are currently ejected.
+func (c *Client) Ejected() (out []int) {
+	for i, n := range c.nodes {
+		n.mu.Lock()
+		if n.ejected {
+			out = append(out, i)
+		}
+		n.mu.Unlock()
+	}
+	return out
+}
+
+// order returns the nodes in the order to try them in, with ejected nodes last.
+func (c *Client) order() []*node {
+	nodes := make([]*node, len(c.nodes))
+	copy(nodes, c.nodes)
+	if c.policy == RoundRobin && len(nodes) > 0 {
+		offset := int(atomic.AddUint32(&c.next, 1)-1) % len(nodes)
+		nodes = append(nodes[offset:], nodes[:offset]...)
+	}
+	type nodeState struct {
+		ejected      bool
+		syncDistance common.Slot
+	}
+	states := make(map[*node]nodeState, len(nodes))
+	for _, n := range nodes {
+		n.mu.Lock()
+		states[n] = nodeState{ejected: n.ejected, syncDistance: n.syncDistance}
+		n.mu.Unlock()
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		a, b := states[nodes[i]], states[nodes[j]]
+		if a.ejected != b.ejected {
+			return !a.ejected
+		}
+		if c.policy == HealthAware {
+			return a.syncDistance < b.syncDistance
+		}
+		return false
+	})
+	return nodes
+}
+
+func (c *Client) eject(n *node, err error) {
+	n.mu.Lock()
+	wasEjected := n.ejected
+	n.ejected = true
+	n.mu.Unlock()
+	if !wasEjected && c.OnEject != nil {
+		for i := range c.nodes {
+			if c.nodes[i] == n {
+				c.OnEject(i, err)
+				break
+			}
+		}
+	}
+}
+
+// retryable checks if a failed request may succeed with another node.
+func retryable(code uint, err error) bool {
+	if err == nil {
+		return false
+	}
+	if code >= 500 {
+		return true
+	}
+	var transportErr eth2api.TransportErr
+	return errors.As(err, &transportErr)
+}
+
+// try runs fn with the response of each node, until the result is not retryable, or all nodes are tried.
+func (c *Client) try(ctx context.Context, req eth2api.PreparedRequest, fn func(resp eth2api.Response) (uint, error)) (code uint, err error) {
+	if len(c.nodes) == 0 {
+		return 0, errors.New("no nodes to request")
+	}
+	for _, n := range c.order() {
+		code, err = fn(n.cli.Request(ctx, req))
+		if ctx.Err() != nil || !retryable(code, err) {
+			return code, err
+		}
+		c.eject(n, err)
+	}
+	return code, err
+}
+
+func (c *Client) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	return &response{c: c, ctx: ctx, req: req}
+}
+
+// response defers the request to Decode or Stream, to try the next node when the response of a node fails.
+type response struct {
+	c       *Client
+	ctx     context.Context
+	req     eth2api.PreparedRequest
+	headers eth2api.Headers
+}
+
+var _ eth2api.StreamResponse = (*response)(nil)
+
+func (r *response) Decode(dest interface{}) (code uint, err error) {
+	return r.c.try(r.ctx, r.req, func(resp eth2api.Response) (uint, error) {
+		r.headers = resp.Headers()
+		return resp.Decode(dest)
+	})
+}
+
+func (r *response) Stream() (code uint, body io.ReadCloser, err error) {
+	code, err = r.c.try(r.ctx, r.req, func(resp eth2api.Response) (code uint, err error) {
+		r.headers = resp.Headers()
+		sr, ok := resp.(eth2api.StreamResponse)
+		if !ok {
+			return resp.Decode(nil)
+		}
+		code, body, err = sr.Stream()
+		return code, err
+	})
+	return code, body, err
+}
+
+// Headers of the last tried node. Only available after Decode or Stream.
+func (r *response) Headers() eth2api.Headers {
+	return r.headers
+}
+
+// Run probes the nodes in the background, until the context is done.
+// Ejected nodes that are healthy again are reinstated.
+// With the HealthAware policy all nodes are probed, to order them by sync distance.
+func (c *Client) Run(ctx context.Context) {
+	interval := c.ProbeInterval
+	if interval == 0 {
+		interval = DefaultProbeInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.probeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Client) probeAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range c.nodes {
+		n.mu.Lock()
+		ejected := n.ejected
+		n.mu.Unlock()
+		if !ejected && c.policy != HealthAware {
+			continue
+		}
+		wg.Add(1)
+		go func(n *node) {
+			defer wg.Done()
+			c.probe(ctx, n)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// probe checks the health of the node, syncing nodes are not healthy.
+func (c *Client) probe(ctx context.Context, n *node) {
+	syncing, err := nodeapi.Health(ctx, n.cli)
+	if err != nil || syncing {
+		if ctx.Err() == nil {
+			c.eject(n, err)
+		}
+		return
+	}
+	var status eth2api.SyncingStatus
+	if c.policy == HealthAware {
+		if err := nodeapi.SyncingStatus(ctx, n.cli, &status); err != nil {
+			if ctx.Err() == nil {
+				c.eject(n, err)
+			}
+			return
+		}
+	}
+	n.mu.Lock()
+	n.ejected = false
+	n.syncDistance = status.SyncDistance
+	n.mu.Unlock()
+}
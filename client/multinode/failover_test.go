@@ -0,0 +1,137 @@
+package multinode
+
+import (
+	"context"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/nodeapi"
+)
+
+type testNode struct {
+	srv      *httptest.Server
+	cli      *eth2api.Eth2HttpClient
+	requests int32
+	// status code to respond with to requests, and to health probes
+	code uint32
+}
+
+func newTestNode(t *testing.T, version string) *testNode {
+	n := &testNode{code: 200}
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/version", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		atomic.AddInt32(&n.requests, 1)
+		switch code := atomic.LoadUint32(&n.code); code {
+		case 200:
+			return eth2api.RespondOK(eth2api.Wrap(&eth2api.NodeVersionResponse{Version: version}))
+		default:
+			return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: uint(code), Message: "failure"})
+		}
+	}))
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/health", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		if code := atomic.LoadUint32(&n.code); code != 200 {
+			return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: uint(code), Message: "failure"})
+		}
+		return eth2api.RespondOKMsg("ok")
+	}))
+	n.srv = httptest.NewServer(router)
+	t.Cleanup(n.srv.Close)
+	n.cli = &eth2api.Eth2HttpClient{Addr: n.srv.URL, Cli: n.srv.Client(), Codec: eth2api.JSONCodec{}}
+	return n
+}
+
+func version(t *testing.T, cli eth2api.Client) (string, error) {
+	var out eth2api.NodeVersionResponse
+	err := nodeapi.NodeVersion(context.Background(), cli, &out)
+	return out.Version, err
+}
+
+func TestPrimaryFallback(t *testing.T) {
+	a, b := newTestNode(t, "a"), newTestNode(t, "b")
+	cli := NewClient(PrimaryFallback, a.cli, b.cli)
+
+	if v, err := version(t, cli); err != nil || v != "a" {
+		t.Fatalf("expected primary, got %q, err: %v", v, err)
+	}
+	atomic.StoreUint32(&a.code, 503)
+	if v, err := version(t, cli); err != nil || v != "b" {
+		t.Fatalf("expected fallback, got %q, err: %v", v, err)
+	}
+	if ejected := cli.Ejected(); len(ejected) != 1 || ejected[0] != 0 {
+		t.Fatalf("expected primary to be ejected, got %v", ejected)
+	}
+	// the ejected primary is not tried again
+	before := atomic.LoadInt32(&a.requests)
+	if v, err := version(t, cli); err != nil || v != "b" {
+		t.Fatalf("expected fallback, got %q, err: %v", v, err)
+	}
+	if atomic.LoadInt32(&a.requests) != before {
+		t.Fatal("ejected node was requested")
+	}
+	// the probe reinstates the primary when it recovers
+	cli.probeAll(context.Background())
+	if len(cli.Ejected()) != 1 {
+		t.Fatal("expected primary to stay ejected")
+	}
+	atomic.StoreUint32(&a.code, 200)
+	cli.probeAll(context.Background())
+	if len(cli.Ejected()) != 0 {
+		t.Fatal("expected primary to be reinstated")
+	}
+	if v, err := version(t, cli); err != nil || v != "a" {
+		t.Fatalf("expected primary, got %q, err: %v", v, err)
+	}
+}
+
+func TestNoRetryOnBadRequest(t *testing.T) {
+	a, b := newTestNode(t, "a"), newTestNode(t, "b")
+	cli := NewClient(PrimaryFallback, a.cli, b.cli)
+	atomic.StoreUint32(&a.code, 400)
+	_, err := version(t, cli)
+	if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 400 {
+		t.Fatalf("expected 400 error, got %v", err)
+	}
+	if atomic.LoadInt32(&b.requests) != 0 {
+		t.Fatal("bad request was retried")
+	}
+	if len(cli.Ejected()) != 0 {
+		t.Fatal("node was ejected for bad request")
+	}
+}
+
+func TestTransportErrorFailover(t *testing.T) {
+	a, b := newTestNode(t, "a"), newTestNode(t, "b")
+	a.srv.Close()
+	cli := NewClient(PrimaryFallback, a.cli, b.cli)
+	if v, err := version(t, cli); err != nil || v != "b" {
+		t.Fatalf("expected fallback, got %q, err: %v", v, err)
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	nodes := []*testNode{newTestNode(t, "a"), newTestNode(t, "b"), newTestNode(t, "c")}
+	cli := NewClient(RoundRobin, nodes[0].cli, nodes[1].cli, nodes[2].cli)
+	for i := 0; i < 6; i++ {
+		if _, err := version(t, cli); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, n := range nodes {
+		if got := atomic.LoadInt32(&n.requests); got != 2 {
+			t.Fatalf("node %d: expected 2 requests, got %d", i, got)
+		}
+	}
+}
+
+func TestAllNodesFail(t *testing.T) {
+	a, b := newTestNode(t, "a"), newTestNode(t, "b")
+	atomic.StoreUint32(&a.code, 500)
+	atomic.StoreUint32(&b.code, 503)
+	cli := NewClient(PrimaryFallback, a.cli, b.cli)
+	_, err := version(t, cli)
+	if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 503 {
+		t.Fatalf("expected error of last node, got %v", err)
+	}
+}
@@ -0,0 +1,288 @@
+package multinode
+
+import (
+	"context"
+	"math"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+const (
+	DefaultHedgePercentile = 0.9
+	DefaultHedgeDelay      = 500 * time.Millisecond
+	// DefaultHedgeMinSamples is the number of latency samples of a node before its hedge delay adapts.
+	DefaultHedgeMinSamples = 20
+)
+
+const (
+	// latency histogram buckets, a quarter octave wide, from 1 ms up to about 65 seconds.
+	latencyBucketsPerOctave = 4
+	latencyBuckets          = 16*latencyBucketsPerOctave + 1
+	latencyBase             = time.Millisecond
+	// the counts are halved when the total reaches this, to adapt to recent latencies.
+	latencyDecayAt = 1000
+)
+
+// latencyHistogram tracks the latency distribution of a node, with exponential buckets.
+type latencyHistogram struct {
+	mu     sync.Mutex
+	counts [latencyBuckets]uint64
+	total  uint64
+}
+
+func latencyBucketBound(i int) time.Duration {
+	return time.Duration(float64(latencyBase) * math.Pow(2, float64(i)/latencyBucketsPerOctave))
+}
+
+func (lh *latencyHistogram) Observe(d time.Duration) {
+	i := 0
+	if d > latencyBase {
+		i = int(math.Ceil(math.Log2(float64(d)/float64(latencyBase)) * latencyBucketsPerOctave))
+		if i >= latencyBuckets {
+			i = latencyBuckets - 1
+		}
+	}
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	lh.counts[i]++
+	lh.total++
+	if lh.total >= latencyDecayAt {
+		lh.total = 0
+		for j := range lh.counts {
+			lh.counts[j] /= 2
+			lh.total += lh.counts[j]
+		}
+	}
+}
+
+// Quantile returns the upper bound of the bucket of the q-quantile, and the number of samples it is based on.
+func (lh *latencyHistogram) Quantile(q float64) (time.Duration, uint64) {
+	lh.mu.Lock()
+	defer lh.mu.Unlock()
+	if lh.total == 0 {
+		return 0, 0
+	}
+	target := uint64(math.Ceil(q * float64(lh.total)))
+	if target == 0 {
+		target = 1
+	}
+	var sum uint64
+	for i, c := range lh.counts {
+		sum += c
+		if sum >= target {
+			return latencyBucketBound(i), lh.total
+		}
+	}
+	return latencyBucketBound(latencyBuckets - 1), lh.total
+}
+
+// Hedged is an eth2api.Client that hedges latency-critical requests:
+// if the first node has not answered within the hedge delay, the request is also sent to another node.
+// The first successful response is used, and the other request is cancelled.
+// A hedge that answers while the first node is still sending its response body does not wait for it:
+// the first request is cancelled, and the response of the hedge is decoded into the destination instead.
+//
+// The hedge delay of a node is the Percentile of its recent latencies, or DefaultDelay while there are few samples.
+// Only GET requests with a path that matches one of the Paths patterns are hedged,
+// other requests are sent to the first node only.
+//
+// Use NewHedged to create a Hedged client with the default hedging parameters.
+// The nodes must not change after the first request.
+type Hedged struct {
+	Nodes []eth2api.Client
+	// Paths are the patterns of the request paths to hedge, matched with path.Match.
+	// E.g. "/eth/v1/validator/attestation_data" or "/eth/v2/validator/blocks/*".
+	Paths []string
+	// Percentile, between 0 and 1, of the latency of the first node to wait for before hedging.
+	Percentile float64
+	// DefaultDelay is the hedge delay, until the node has MinSamples latency samples.
+	DefaultDelay time.Duration
+	MinSamples   uint64
+
+	latenciesOnce sync.Once
+	latencies     []latencyHistogram
+}
+
+var _ eth2api.Client = (*Hedged)(nil)
+
+func NewHedged(paths []string, nodes ...eth2api.Client) *Hedged {
+	return &Hedged{
+		Nodes:        nodes,
+		Paths:        paths,
+		Percentile:   DefaultHedgePercentile,
+		DefaultDelay: DefaultHedgeDelay,
+		MinSamples:   DefaultHedgeMinSamples,
+	}
+}
+
+func (h *Hedged) hedges(req eth2api.PreparedRequest) bool {
+	if req.Method() != eth2api.GET {
+		return false
+	}
+	for _, pattern := range h.Paths {
+		if ok, _ := path.Match(pattern, req.Path()); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// latency returns the latency histogram of the given node, the histograms are allocated on first use.
+func (h *Hedged) latency(node int) *latencyHistogram {
+	h.latenciesOnce.Do(func() {
+		h.latencies = make([]latencyHistogram, len(h.Nodes))
+	})
+	return &h.latencies[node]
+}
+
+// Latency returns the q-quantile of the latency of the given node, and the number of samples it is based on.
+func (h *Hedged) Latency(node int, q float64) (time.Duration, uint64) {
+	return h.latency(node).Quantile(q)
+}
+
+func (h *Hedged) delay(node int) time.Duration {
+	d, n := h.latency(node).Quantile(h.Percentile)
+	if n < h.MinSamples {
+		return h.DefaultDelay
+	}
+	return d
+}
+
+// hedgeNode picks the node to hedge with: the other node with the lowest latency.
+func (h *Hedged) hedgeNode(primary int) int {
+	best := -1
+	var bestLatency time.Duration
+	for i := range h.Nodes {
+		if i == primary {
+			continue
+		}
+		d, n := h.latency(i).Quantile(h.Percentile)
+		if n < h.MinSamples {
+			d = h.DefaultDelay
+		}
+		if best < 0 || d < bestLatency {
+			best, bestLatency = i, d
+		}
+	}
+	return best
+}
+
+func (h *Hedged) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	if len(h.Nodes) == 0 {
+		return noNodesResponse{}
+	}
+	if len(h.Nodes) < 2 || !h.hedges(req) {
+		return h.Nodes[0].Request(ctx, req)
+	}
+	return &hedgedResponse{h: h, ctx: ctx, req: req}
+}
+
+type hedgedResponse struct {
+	h       *Hedged
+	ctx     context.Context
+	req     eth2api.PreparedRequest
+	mu      sync.Mutex
+	headers eth2api.Headers
+}
+
+type hedgeResult struct {
+	code uint
+	err  error
+}
+
+func (r *hedgedResponse) Decode(dest interface{}) (code uint, err error) {
+	h := r.h
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, 2)
+	// Holding the dest token is required to decode into the destination, one node at a time.
+	// Once won is true, the destination is not written to anymore.
+	destToken := make(chan struct{}, 1)
+	won := false
+	// The primary request can be cancelled separately, when the hedge is ready before the primary is decoded.
+	primaryCtx, cancelPrimary := context.WithCancel(ctx)
+	defer cancelPrimary()
+	attempt := func(node int, hedge bool) {
+		start := time.Now()
+		reqCtx := primaryCtx
+		if hedge {
+			reqCtx = ctx
+		}
+		resp := h.Nodes[node].Request(reqCtx, r.req)
+		if hedge {
+			// Don't wait for a slow response body of the primary: cancel it, the hedge decodes into the destination.
+			select {
+			case destToken <- struct{}{}:
+			default:
+				cancelPrimary()
+				destToken <- struct{}{}
+			}
+		} else {
+			destToken <- struct{}{}
+		}
+		// Only the first success is decoded into the destination, the loser is just closed.
+		var res hedgeResult
+		// A cancelled request is not a latency sample: the loser may be cancelled before it could answer.
+		var cancelled bool
+		if won {
+			<-destToken
+			res.code, res.err = resp.Decode(nil)
+			cancelled = reqCtx.Err() != nil
+		} else {
+			res.code, res.err = resp.Decode(dest)
+			cancelled = reqCtx.Err() != nil
+			if res.err == nil {
+				r.mu.Lock()
+				won = true
+				r.headers = resp.Headers()
+				r.mu.Unlock()
+				// cancel the loser
+				cancel()
+			}
+			<-destToken
+		}
+		if !cancelled {
+			h.latency(node).Observe(time.Since(start))
+		}
+		results <- res
+	}
+
+	primary := 0
+	go attempt(primary, false)
+	timer := time.NewTimer(h.delay(primary))
+	defer timer.Stop()
+	pending, hedged := 1, false
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if !hedged {
+				hedged = true
+				pending++
+				go attempt(h.hedgeNode(primary), true)
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				return res.code, nil
+			}
+			code, err = res.code, res.err
+			// Don't wait for the hedge delay if the first node fails already.
+			if !hedged && r.ctx.Err() == nil && retryable(res.code, res.err) {
+				hedged = true
+				pending++
+				go attempt(h.hedgeNode(primary), true)
+			}
+		}
+	}
+	return code, err
+}
+
+func (r *hedgedResponse) Headers() eth2api.Headers {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.headers
+}
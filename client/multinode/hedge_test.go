@@ -0,0 +1,136 @@
+package multinode
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+func newSlowNode(t *testing.T, name string, delay time.Duration, cancelled *int32) eth2api.Client {
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/version", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			atomic.AddInt32(cancelled, 1)
+		}
+		return eth2api.RespondOK(eth2api.Wrap(&eth2api.NodeVersionResponse{Version: name}))
+	}))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+}
+
+func TestHedged(t *testing.T) {
+	var cancelled int32
+	h := NewHedged([]string{"/eth/v1/node/*"},
+		newSlowNode(t, "slow", 5*time.Second, &cancelled),
+		newSlowNode(t, "fast", 0, &cancelled))
+	h.DefaultDelay = 10 * time.Millisecond
+	start := time.Now()
+	if v, err := version(t, h); err != nil || v != "fast" {
+		t.Fatalf("expected hedged response, got %q, err: %v", v, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("hedged request waited for slow node")
+	}
+	// the slow request is cancelled
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&cancelled) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected slow request to be cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, n := h.Latency(1, 0.5); n != 1 {
+		t.Fatalf("expected a latency sample of the fast node, got %d", n)
+	}
+	// the cancelled request of the loser is not a latency sample
+	time.Sleep(20 * time.Millisecond)
+	if _, n := h.Latency(0, 0.5); n != 0 {
+		t.Fatalf("expected no latency sample of the slow node, got %d", n)
+	}
+}
+
+func TestHedgedSlowBody(t *testing.T) {
+	// the first node answers right away, but sends its response body slowly
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(`{"data": {"version": "sl`))
+		w.(http.Flusher).Flush()
+		select {
+		case <-time.After(5 * time.Second):
+		case <-req.Context().Done():
+		}
+		_, _ = w.Write([]byte(`ow"}}`))
+	}))
+	t.Cleanup(srv.Close)
+	var cancelled int32
+	h := NewHedged([]string{"/eth/v1/node/*"},
+		&eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}},
+		newSlowNode(t, "fast", 0, &cancelled))
+	h.DefaultDelay = 10 * time.Millisecond
+	start := time.Now()
+	if v, err := version(t, h); err != nil || v != "fast" {
+		t.Fatalf("expected hedged response, got %q, err: %v", v, err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("hedged request waited for the body of the slow node")
+	}
+	// the cancelled request of the first node is not a latency sample
+	time.Sleep(20 * time.Millisecond)
+	if _, n := h.Latency(0, 0.5); n != 0 {
+		t.Fatalf("expected no latency sample of the slow node, got %d", n)
+	}
+}
+
+func TestHedgedZeroValue(t *testing.T) {
+	var cancelled int32
+	h := &Hedged{
+		Nodes: []eth2api.Client{newSlowNode(t, "a", 0, &cancelled), newSlowNode(t, "b", 0, &cancelled)},
+		Paths: []string{"/eth/v1/node/*"},
+	}
+	if _, err := version(t, h); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHedgedOptIn(t *testing.T) {
+	var cancelled int32
+	h := NewHedged([]string{"/eth/v1/validator/attestation_data"},
+		newSlowNode(t, "slow", 50*time.Millisecond, &cancelled),
+		newSlowNode(t, "fast", 0, &cancelled))
+	h.DefaultDelay = time.Millisecond
+	if v, err := version(t, h); err != nil || v != "slow" {
+		t.Fatalf("expected request to first node only, got %q, err: %v", v, err)
+	}
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var lh latencyHistogram
+	for i := 0; i < 90; i++ {
+		lh.Observe(10 * time.Millisecond)
+	}
+	for i := 0; i < 10; i++ {
+		lh.Observe(time.Second)
+	}
+	if d, n := lh.Quantile(0.5); n != 100 || d < 10*time.Millisecond || d > 12*time.Millisecond {
+		t.Fatalf("unexpected median: %s (%d samples)", d, n)
+	}
+	if d, _ := lh.Quantile(0.95); d < time.Second || d > 1200*time.Millisecond {
+		t.Fatalf("unexpected p95: %s", d)
+	}
+	// old samples decay, to adapt to recent latencies
+	for i := 0; i < 2000; i++ {
+		lh.Observe(time.Second)
+	}
+	if d, _ := lh.Quantile(0.5); d < time.Second {
+		t.Fatalf("expected median to adapt, got %s", d)
+	}
+}
@@ -22,10 +22,10 @@ func Peers(ctx context.Context, cli eth2api.Client, state []eth2api.ConnectionSt
 	if state != nil || direction != nil {
 		q = make(eth2api.Query)
 		if state != nil {
-			q["state"] = state
+			q["state"] = eth2api.ConnectionStateFilter(state)
 		}
 		if direction != nil {
-			q["direction"] = direction
+			q["direction"] = eth2api.ConnectionDirectionFilter(direction)
 		}
 	}
 	// TODO: current spec has half-removed pagination. A "meta" field alongside the "data" field.
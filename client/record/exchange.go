@@ -0,0 +1,108 @@
+package record
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Exchange is a recorded request and response, in the format of the JSON test vectors (see shared_test),
+// extended with the method, query, response headers and non-JSON response bodies.
+type Exchange struct {
+	Description string            `json:"description,omitempty"`
+	Method      eth2api.ReqMethod `json:"method,omitempty"`
+	// Path of the request, including the encoded query, if any.
+	Path  string            `json:"path"`
+	Query map[string]string `json:"query,omitempty"`
+	// PostBody is the JSON encoded request body, as sent by the client.
+	PostBody string          `json:"post_body,omitempty"`
+	Code     uint            `json:"code"`
+	Headers  eth2api.Headers `json:"headers,omitempty"`
+	// Response is the raw JSON response body.
+	Response json.RawMessage `json:"response,omitempty"`
+	// RawResponse is the response body if it is not JSON, e.g. SSZ.
+	RawResponse []byte `json:"raw_response,omitempty"`
+}
+
+func (e *Exchange) body() []byte {
+	if e.RawResponse != nil {
+		return e.RawResponse
+	}
+	return e.Response
+}
+
+// ReadExchanges reads exchanges, either a JSON list like the test vectors, or JSONL, with an exchange per line.
+func ReadExchanges(r io.Reader) ([]*Exchange, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			_, _ = br.ReadByte()
+			continue
+		}
+		break
+	}
+	dec := json.NewDecoder(br)
+	var out []*Exchange
+	if b, _ := br.Peek(1); b[0] == '[' {
+		if err := dec.Decode(&out); err != nil {
+			return nil, fmt.Errorf("failed to decode exchanges: %w", err)
+		}
+		return out, nil
+	}
+	for {
+		var ex Exchange
+		if err := dec.Decode(&ex); err == io.EOF {
+			return out, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to decode exchange %d: %w", len(out), err)
+		}
+		out = append(out, &ex)
+	}
+}
+
+// WriteExchanges writes the exchanges as an indented JSON list, like the test vectors.
+func WriteExchanges(w io.Writer, exchanges []*Exchange) error {
+	if exchanges == nil {
+		exchanges = []*Exchange{}
+	}
+	data, err := json.MarshalIndent(exchanges, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func encodeQuery(q eth2api.Query) (string, map[string]string, error) {
+	if len(q) == 0 {
+		return "", nil, nil
+	}
+	values, err := q.Values()
+	if err != nil {
+		return "", nil, err
+	}
+	m := make(map[string]string, len(values))
+	for k := range values {
+		m[k] = values.Get(k)
+	}
+	return "?" + values.Encode(), m, nil
+}
+
+func encodeBody(body interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := (eth2api.JSONCodec{}).EncodeRequestBody(&buf, body); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
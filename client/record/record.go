@@ -0,0 +1,120 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Recorder is an eth2api.Client decorator that records the requests and responses.
+// Each exchange is written as a line of JSON to the optional writer (JSONL), as soon as the response is received.
+// All exchanges are also kept, to write them as JSON list with WriteExchanges.
+//
+// Only responses of the Eth2HttpClient are recorded, other responses pass through unrecorded.
+type Recorder struct {
+	Client eth2api.Client
+
+	mu        sync.Mutex
+	w         io.Writer
+	exchanges []*Exchange
+	err       error
+}
+
+var _ eth2api.Client = (*Recorder)(nil)
+
+// NewRecorder creates a Recorder, w may be nil to only keep the exchanges in memory.
+func NewRecorder(cli eth2api.Client, w io.Writer) *Recorder {
+	return &Recorder{Client: cli, w: w}
+}
+
+// Exchanges returns the exchanges recorded so far.
+func (r *Recorder) Exchanges() []*Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Exchange(nil), r.exchanges...)
+}
+
+// Err returns the first error that occurred while writing exchanges, if any.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) add(ex *Exchange) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, ex)
+	if r.w == nil || r.err != nil {
+		return
+	}
+	data, err := json.Marshal(ex)
+	if err == nil {
+		_, err = r.w.Write(append(data, '\n'))
+	}
+	if err != nil {
+		r.err = fmt.Errorf("failed to write exchange: %w", err)
+	}
+}
+
+func (r *Recorder) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	resp := r.Client.Request(ctx, req)
+	hr, ok := resp.(*eth2api.HttpResponse)
+	if !ok {
+		return resp
+	}
+	query, queryMap, err := encodeQuery(req.Query())
+	if err != nil {
+		return resp
+	}
+	ex := &Exchange{
+		Method:  req.Method(),
+		Path:    req.Path() + query,
+		Query:   queryMap,
+		Code:    uint(hr.StatusCode),
+		Headers: hr.Headers(),
+	}
+	if req.Method() == eth2api.POST {
+		if ex.PostBody, err = encodeBody(req.Body()); err != nil {
+			return resp
+		}
+	}
+	body, err := io.ReadAll(hr.Body)
+	_ = hr.Body.Close()
+	if err != nil {
+		return errResponse{fmt.Errorf("failed to read response body: %w", err)}
+	}
+	if trimmed := bytes.TrimSpace(body); json.Valid(trimmed) {
+		ex.Response = trimmed
+	} else {
+		ex.RawResponse = body
+	}
+	r.add(ex)
+	// the body is consumed by the recording, replay it to the caller.
+	return &eth2api.HttpResponse{
+		Response: &http.Response{
+			StatusCode: hr.StatusCode,
+			Header:     hr.Header,
+			Body:       io.NopCloser(bytes.NewReader(body)),
+		},
+		Codec: hr.Codec,
+	}
+}
+
+type errResponse struct {
+	err error
+}
+
+func (r errResponse) Decode(dest interface{}) (uint, error) {
+	return 0, r.err
+}
+
+func (r errResponse) Headers() eth2api.Headers {
+	return nil
+}
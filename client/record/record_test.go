@@ -0,0 +1,103 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/eth2api/client/nodeapi"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+func newTestNode(t *testing.T) eth2api.Client {
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/version", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		return eth2api.RespondOK(eth2api.Wrap(&eth2api.NodeVersionResponse{Version: "test/v1"}))
+	}))
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/peers", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		states, _ := req.Query("state")
+		return eth2api.RespondOK(eth2api.Wrap([]eth2api.Peer{{PeerID: eth2api.ApiPeerId(states[0])}}))
+	}))
+	router.AddRoute(eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/voluntary_exits", func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+		return eth2api.RespondNotFound("not here")
+	}))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+}
+
+// session is the traffic that is recorded and replayed.
+func session(t *testing.T, cli eth2api.Client) {
+	ctx := context.Background()
+	var version eth2api.NodeVersionResponse
+	if err := nodeapi.NodeVersion(ctx, cli, &version); err != nil {
+		t.Fatal(err)
+	}
+	if version.Version != "test/v1" {
+		t.Fatalf("unexpected version: %q", version.Version)
+	}
+	var peers []eth2api.Peer
+	if err := nodeapi.Peers(ctx, cli, []eth2api.ConnectionState{eth2api.ConnectionStateConnected}, nil, &peers); err != nil {
+		t.Fatal(err)
+	}
+	if len(peers) != 1 || peers[0].PeerID != "connected" {
+		t.Fatalf("unexpected peers: %v", peers)
+	}
+	exit := &phase0.SignedVoluntaryExit{Message: phase0.VoluntaryExit{Epoch: 1, ValidatorIndex: 2}}
+	err := beaconapi.SubmitVoluntaryExit(ctx, cli, exit)
+	if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 404 {
+		t.Fatalf("expected 404 error, got %v", err)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	var jsonl bytes.Buffer
+	rec := NewRecorder(newTestNode(t), &jsonl)
+	session(t, rec)
+	if err := rec.Err(); err != nil {
+		t.Fatal(err)
+	}
+	exchanges, err := ReadExchanges(&jsonl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+	if ex := exchanges[1]; ex.Path != "/eth/v1/node/peers?state=connected" || ex.Query["state"] != "connected" || ex.Method != eth2api.GET {
+		t.Fatalf("unexpected peers exchange: %+v", ex)
+	}
+	if ex := exchanges[2]; ex.Code != 404 || ex.PostBody == "" || ex.Headers["Content-Type"] != "application/json" {
+		t.Fatalf("unexpected exit exchange: %+v", ex)
+	}
+
+	// the JSON list format round-trips too
+	var list bytes.Buffer
+	if err := WriteExchanges(&list, rec.Exchanges()); err != nil {
+		t.Fatal(err)
+	}
+	if exchanges, err = ReadExchanges(&list); err != nil || len(exchanges) != 3 {
+		t.Fatalf("failed to read JSON list: %d exchanges, err: %v", len(exchanges), err)
+	}
+
+	replay := NewReplay(exchanges, true)
+	session(t, replay)
+	if replay.Remaining() != 0 {
+		t.Fatalf("expected all exchanges to be replayed, %d remaining", replay.Remaining())
+	}
+	// strict replay fails on requests out of order
+	if err := nodeapi.NodeVersion(context.Background(), NewReplay(exchanges[1:], true), new(eth2api.NodeVersionResponse)); err == nil {
+		t.Fatal("expected strict replay to fail")
+	}
+	// lenient replay serves requests in any order, any number of times
+	lenient := NewReplay(exchanges, false)
+	for i := 0; i < 2; i++ {
+		if err := nodeapi.NodeVersion(context.Background(), lenient, new(eth2api.NodeVersionResponse)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	session(t, lenient)
+}
@@ -0,0 +1,112 @@
+package record
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Replay is an eth2api.Client that serves recorded exchanges, e.g. to run regression tests offline.
+//
+// In strict mode the requests must match the recorded exchanges in order,
+// including the method, path, query and POST body.
+// In lenient mode the exchanges are matched by method, path and query, in any order,
+// the first exchange that was not replayed yet is preferred, and exchanges may be replayed multiple times.
+//
+// Requests that do not match result in an error.
+type Replay struct {
+	Exchanges []*Exchange
+	Strict    bool
+	// Codecs to decode responses with, by their Content-Type header. Optional, JSON is used by default.
+	Codecs eth2api.Codecs
+
+	mu   sync.Mutex
+	next int
+	used map[*Exchange]bool
+}
+
+var _ eth2api.Client = (*Replay)(nil)
+
+func NewReplay(exchanges []*Exchange, strict bool) *Replay {
+	return &Replay{Exchanges: exchanges, Strict: strict}
+}
+
+// Remaining returns the number of exchanges that were not replayed yet.
+func (r *Replay) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.Exchanges) - len(r.used)
+}
+
+func (r *Replay) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	query, _, err := encodeQuery(req.Query())
+	if err != nil {
+		return errResponse{err}
+	}
+	method := req.Method()
+	p := req.Path() + query
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.used == nil {
+		r.used = make(map[*Exchange]bool)
+	}
+	var match *Exchange
+	if r.Strict {
+		if r.next >= len(r.Exchanges) {
+			return errResponse{fmt.Errorf("unexpected request %s %s, all %d exchanges were replayed", method, p, len(r.Exchanges))}
+		}
+		ex := r.Exchanges[r.next]
+		if ex.Method != "" && ex.Method != method || ex.Path != p {
+			return errResponse{fmt.Errorf("unexpected request %s %s, expected exchange %d: %s %s", method, p, r.next, ex.Method, ex.Path)}
+		}
+		if method == eth2api.POST {
+			body, err := encodeBody(req.Body())
+			if err != nil {
+				return errResponse{err}
+			}
+			if body != ex.PostBody {
+				return errResponse{fmt.Errorf("unexpected POST body of request %s, expected exchange %d:\ngot:\n%s\nexpected:\n%s", p, r.next, body, ex.PostBody)}
+			}
+		}
+		r.next++
+		match = ex
+	} else {
+		for _, ex := range r.Exchanges {
+			if (ex.Method == "" || ex.Method == method) && ex.Path == p {
+				if match == nil || (r.used[match] && !r.used[ex]) {
+					match = ex
+				}
+			}
+		}
+		if match == nil {
+			return errResponse{fmt.Errorf("no recorded exchange for request %s %s", method, p)}
+		}
+	}
+	r.used[match] = true
+	return r.response(match)
+}
+
+func (r *Replay) response(ex *Exchange) eth2api.Response {
+	header := make(http.Header, len(ex.Headers))
+	for k, v := range ex.Headers {
+		header.Set(k, v)
+	}
+	codec, ok := r.Codecs.ByContentType(header.Get("Content-Type"))
+	if !ok {
+		codec = eth2api.JSONCodec{}
+	}
+	return &eth2api.HttpResponse{
+		Response: &http.Response{
+			StatusCode: int(ex.Code),
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewReader(ex.body())),
+		},
+		Codec: codec,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/protolambda/eth2api"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/ztyp/view"
 )
 
 // Requests a beacon node to produce a valid phase0 block, which can then be signed by a validator.
@@ -45,3 +46,51 @@ func ProduceBlockV2(ctx context.Context, cli eth2api.Client,
 	syncing = code == 503
 	return
 }
+
+// Requests a beacon node to produce a valid blinded block, with the execution payload header of a builder,
+// which can then be signed by a validator.
+//
+// Err will be non-nil when syncing.
+func ProduceBlindedBlock(ctx context.Context, cli eth2api.Client,
+	slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root, dest *eth2api.VersionedBlindedBeaconBlock) (syncing bool, err error) {
+	q := eth2api.Query{
+		"randao_reveal": randaoReveal,
+	}
+	if graffiti != nil {
+		q["graffiti"] = graffiti
+	}
+	req := eth2api.FmtQueryGET(q, "/eth/v1/validator/blinded_blocks/%d", slot)
+	resp := cli.Request(ctx, req)
+	var code uint
+	code, err = resp.Decode(dest)
+	syncing = code == 503
+	return
+}
+
+// Requests a beacon node to produce a valid block, which can then be signed by a validator.
+// The block is blinded if the node chose the execution payload of a builder, see dest.ExecutionPayloadBlinded.
+//
+// The builder boost factor is optional: a percentage multiplier of the builder payload value,
+// to compare it with the value of the local payload.
+//
+// Err will be non-nil when syncing.
+func ProduceBlockV3(ctx context.Context, cli eth2api.Client,
+	slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root, builderBoostFactor *uint64,
+	dest *eth2api.ProducedBlock) (syncing bool, err error) {
+	q := eth2api.Query{
+		"randao_reveal": randaoReveal,
+	}
+	if graffiti != nil {
+		q["graffiti"] = graffiti
+	}
+	if builderBoostFactor != nil {
+		q["builder_boost_factor"] = view.Uint64View(*builderBoostFactor)
+	}
+	req := eth2api.FmtQueryGET(q, "/eth/v3/validator/blocks/%d", slot)
+	resp := cli.Request(ctx, req)
+	// SSZ responses only specify the block type in the headers, these are preset by the response when decoding.
+	var code uint
+	code, err = resp.Decode(dest)
+	syncing = code == 503
+	return
+}
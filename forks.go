@@ -0,0 +1,200 @@
+package eth2api
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	"github.com/protolambda/zrnt/eth2/beacon/capella"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/beacon/electra"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/ztyp/codec"
+)
+
+// Fork describes the types of a fork, as used by the versioned API types.
+type Fork struct {
+	// Name of the fork, as used in the version field of versioned objects and the Eth-Consensus-Version header.
+	Name string
+	// Version of the fork in the given spec.
+	Version func(spec *common.Spec) common.Version
+	// Epoch of the fork in the given spec, common.FAR_FUTURE_EPOCH if not scheduled.
+	Epoch func(spec *common.Spec) common.Epoch
+
+	BeaconBlock       func() common.SpecObj
+	SignedBeaconBlock func() SignedBeaconBlock
+	// BlockContents allocates the produced block of block proposals:
+	// the block itself before deneb, *BlockContents with blobs since.
+	BlockContents func() common.SpecObj
+	// SignedBlockContents allocates the signed block of block publishing:
+	// the signed block itself before deneb, *SignedBlockContents with blobs since.
+	SignedBlockContents func() common.SpecObj
+	// BlindedBeaconBlockBody allocates the blinded block body of the builder flow, nil before bellatrix.
+	BlindedBeaconBlockBody func() common.SpecObj
+	// BlindedBeaconBlock and SignedBlindedBeaconBlock allocate blinded blocks with the blinded body of the fork,
+	// nil before bellatrix.
+	BlindedBeaconBlock       func() *BlindedBeaconBlock
+	SignedBlindedBeaconBlock func() *SignedBlindedBeaconBlock
+	BeaconState              func() common.SpecObj
+	// BeaconStateView decodes a binary-tree backed state.
+	BeaconStateView func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error)
+
+	// types to recognize the fork of signed blocks and state views by
+	signedBlockType reflect.Type
+	stateViewType   reflect.Type
+}
+
+// Forks lists all known forks, in order of activation.
+var Forks = []*Fork{
+	{
+		Name:              "phase0",
+		Version:           func(spec *common.Spec) common.Version { return spec.GENESIS_FORK_VERSION },
+		Epoch:             func(spec *common.Spec) common.Epoch { return common.GENESIS_EPOCH },
+		BeaconBlock:       func() common.SpecObj { return new(phase0.BeaconBlock) },
+		SignedBeaconBlock: func() SignedBeaconBlock { return new(phase0.SignedBeaconBlock) },
+		BeaconState:       func() common.SpecObj { return new(phase0.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return phase0.AsBeaconStateView(phase0.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*phase0.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*phase0.BeaconStateView)(nil)),
+	},
+	{
+		Name:              "altair",
+		Version:           func(spec *common.Spec) common.Version { return spec.ALTAIR_FORK_VERSION },
+		Epoch:             func(spec *common.Spec) common.Epoch { return spec.ALTAIR_FORK_EPOCH },
+		BeaconBlock:       func() common.SpecObj { return new(altair.BeaconBlock) },
+		SignedBeaconBlock: func() SignedBeaconBlock { return new(altair.SignedBeaconBlock) },
+		BeaconState:       func() common.SpecObj { return new(altair.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return altair.AsBeaconStateView(altair.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*altair.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*altair.BeaconStateView)(nil)),
+	},
+	{
+		Name:                   "bellatrix",
+		Version:                func(spec *common.Spec) common.Version { return spec.BELLATRIX_FORK_VERSION },
+		Epoch:                  func(spec *common.Spec) common.Epoch { return spec.BELLATRIX_FORK_EPOCH },
+		BeaconBlock:            func() common.SpecObj { return new(bellatrix.BeaconBlock) },
+		SignedBeaconBlock:      func() SignedBeaconBlock { return new(bellatrix.SignedBeaconBlock) },
+		BlindedBeaconBlockBody: func() common.SpecObj { return new(BellatrixBlindedBeaconBlockBody) },
+		BeaconState:            func() common.SpecObj { return new(bellatrix.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return bellatrix.AsBeaconStateView(bellatrix.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*bellatrix.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*bellatrix.BeaconStateView)(nil)),
+	},
+	{
+		Name:                   "capella",
+		Version:                func(spec *common.Spec) common.Version { return spec.CAPELLA_FORK_VERSION },
+		Epoch:                  func(spec *common.Spec) common.Epoch { return spec.CAPELLA_FORK_EPOCH },
+		BeaconBlock:            func() common.SpecObj { return new(capella.BeaconBlock) },
+		SignedBeaconBlock:      func() SignedBeaconBlock { return new(capella.SignedBeaconBlock) },
+		BlindedBeaconBlockBody: func() common.SpecObj { return new(CapellaBlindedBeaconBlockBody) },
+		BeaconState:            func() common.SpecObj { return new(capella.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return capella.AsBeaconStateView(capella.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*capella.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*capella.BeaconStateView)(nil)),
+	},
+	{
+		Name:                   "deneb",
+		Version:                func(spec *common.Spec) common.Version { return spec.DENEB_FORK_VERSION },
+		Epoch:                  func(spec *common.Spec) common.Epoch { return spec.DENEB_FORK_EPOCH },
+		BeaconBlock:            func() common.SpecObj { return new(deneb.BeaconBlock) },
+		SignedBeaconBlock:      func() SignedBeaconBlock { return new(deneb.SignedBeaconBlock) },
+		BlindedBeaconBlockBody: func() common.SpecObj { return new(DenebBlindedBeaconBlockBody) },
+		BlockContents: func() common.SpecObj {
+			return &BlockContents{Block: new(deneb.BeaconBlock)}
+		},
+		SignedBlockContents: func() common.SpecObj {
+			return &SignedBlockContents{SignedBlock: new(deneb.SignedBeaconBlock)}
+		},
+		BeaconState: func() common.SpecObj { return new(deneb.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return deneb.AsBeaconStateView(deneb.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*deneb.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*deneb.BeaconStateView)(nil)),
+	},
+	{
+		Name:                   "electra",
+		Version:                func(spec *common.Spec) common.Version { return spec.ELECTRA_FORK_VERSION },
+		Epoch:                  func(spec *common.Spec) common.Epoch { return spec.ELECTRA_FORK_EPOCH },
+		BeaconBlock:            func() common.SpecObj { return new(electra.BeaconBlock) },
+		SignedBeaconBlock:      func() SignedBeaconBlock { return new(electra.SignedBeaconBlock) },
+		BlindedBeaconBlockBody: func() common.SpecObj { return new(ElectraBlindedBeaconBlockBody) },
+		BlockContents: func() common.SpecObj {
+			return &BlockContents{Block: new(electra.BeaconBlock)}
+		},
+		SignedBlockContents: func() common.SpecObj {
+			return &SignedBlockContents{SignedBlock: new(electra.SignedBeaconBlock)}
+		},
+		BeaconState: func() common.SpecObj { return new(electra.BeaconState) },
+		BeaconStateView: func(spec *common.Spec, dr *codec.DecodingReader) (common.BeaconState, error) {
+			return electra.AsBeaconStateView(electra.BeaconStateType(spec).Deserialize(dr))
+		},
+		signedBlockType: reflect.TypeOf((*electra.SignedBeaconBlock)(nil)),
+		stateViewType:   reflect.TypeOf((*electra.BeaconStateView)(nil)),
+	},
+}
+
+var (
+	forksByName            = make(map[string]*Fork)
+	forksBySignedBlockType = make(map[reflect.Type]*Fork)
+	forksByStateViewType   = make(map[reflect.Type]*Fork)
+)
+
+func init() {
+	for _, f := range Forks {
+		// before deneb the block contents are just the block
+		if f.BlockContents == nil {
+			f.BlockContents = f.BeaconBlock
+		}
+		if f.SignedBlockContents == nil {
+			signed := f.SignedBeaconBlock
+			f.SignedBlockContents = func() common.SpecObj { return signed() }
+		}
+		if body := f.BlindedBeaconBlockBody; body != nil {
+			f.BlindedBeaconBlock = func() *BlindedBeaconBlock {
+				return &BlindedBeaconBlock{Body: body()}
+			}
+			f.SignedBlindedBeaconBlock = func() *SignedBlindedBeaconBlock {
+				return &SignedBlindedBeaconBlock{Message: BlindedBeaconBlock{Body: body()}}
+			}
+		}
+		forksByName[f.Name] = f
+		forksBySignedBlockType[f.signedBlockType] = f
+		forksByStateViewType[f.stateViewType] = f
+	}
+}
+
+// ForkByName returns the fork with the given name, case-insensitive.
+func ForkByName(name string) (*Fork, error) {
+	if f, ok := forksByName[strings.ToLower(name)]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unrecognized version: %q", name)
+}
+
+// ForkBySignedBeaconBlock returns the fork of the signed block type.
+func ForkBySignedBeaconBlock(block common.SpecObj) (*Fork, error) {
+	if f, ok := forksBySignedBlockType[reflect.TypeOf(block)]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unknown block type %T", block)
+}
+
+// ForkByBeaconState returns the fork of the binary-tree backed state type.
+func ForkByBeaconState(state common.BeaconState) (*Fork, error) {
+	if f, ok := forksByStateViewType[reflect.TypeOf(state)]; ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("unknown state type %T", state)
+}
@@ -1,10 +1,11 @@
 module github.com/protolambda/eth2api
 
-go 1.18
+go 1.21
 
 require (
 	github.com/julienschmidt/httprouter v1.3.0
-	github.com/protolambda/zrnt v0.29.0
+	github.com/protolambda/bls12-381-util v0.1.0
+	github.com/protolambda/zrnt v0.34.1
 	github.com/protolambda/ztyp v0.2.2
 )
 
@@ -14,8 +15,7 @@ require (
 	github.com/klauspost/cpuid/v2 v2.2.2 // indirect
 	github.com/kr/pretty v0.3.1 // indirect
 	github.com/minio/sha256-simd v1.0.0 // indirect
-	github.com/protolambda/bls12-381-util v0.0.0-20220416220906-d8552aa452c7 // indirect
-	golang.org/x/sys v0.3.0 // indirect
+	golang.org/x/sys v0.17.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -3,10 +3,15 @@ package eth2api
 import (
 	"bytes"
 	"context"
-	"encoding"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 )
@@ -19,64 +24,103 @@ type Eth2HttpClient struct {
 	Addr  string
 	Cli   HTTPClient
 	Codec Codec
+	// Accept lists the codecs that responses may be served with, in order of preference.
+	// Each response is decoded with the codec that matches its Content-Type.
+	// Optional, Codec is used if empty or if no codec matches.
+	Accept Codecs
+	// Retry policy, optional. Requests are not retried if nil.
+	Retry *RetryPolicy
+}
+
+func (cli *Eth2HttpClient) acceptHeader() []string {
+	if len(cli.Accept) == 0 {
+		return cli.Codec.ContentType()
+	}
+	return []string{cli.Accept.Accept()}
+}
+
+func (cli *Eth2HttpClient) responseCodec(resp *http.Response) Codec {
+	if c, ok := cli.Accept.ByContentType(resp.Header.Get("Content-Type")); ok {
+		return c
+	}
+	return cli.Codec
 }
 
 func (cli *Eth2HttpClient) Request(ctx context.Context, req PreparedRequest) Response {
 	path := cli.Addr + req.Path()
 	if q := req.Query(); q != nil {
-		b := make(url.Values)
-		for k, v := range req.Query() {
-			if s, ok := v.(string); ok {
-				b.Set(k, s)
-			} else if sv, ok := v.(fmt.Stringer); ok {
-				b.Set(k, sv.String())
-			} else if tm, ok := v.(encoding.TextMarshaler); ok {
-				tb, err := tm.MarshalText()
-				if err != nil {
-					return ClientErr{fmt.Errorf("failed to encode query key %s: %w", k, err)}
-				}
-				b.Set(k, string(tb))
-			} else {
-				return ClientErr{fmt.Errorf("failed to encode query key '%s': unknown type", k)}
-			}
+		b, err := q.Values()
+		if err != nil {
+			return ClientErr{err}
 		}
 		path += "?" + b.Encode()
 	}
+	var extraHeaders Headers
+	if hr, ok := req.(HeadersRequest); ok {
+		extraHeaders = hr.Headers()
+	}
 	method := req.Method()
-	switch method {
-	case GET:
-		req, err := http.NewRequestWithContext(ctx, "GET", path, nil)
-		if err != nil {
-			return ClientErr{fmt.Errorf("failed to build GET request: %w", err)}
-		}
-		req.Header = map[string][]string{
-			"Content-Type": cli.Codec.ContentType(),
-		}
-		resp, err := cli.Cli.Do(req)
+	if method != GET && method != POST {
+		return ClientErr{fmt.Errorf("unrecognized request method enum value: %s", method)}
+	}
+	retry := cli.Retry
+	if retry == nil || !retry.retries(req) {
+		retry = &noRetry
+	}
+	for attempt := 1; ; attempt++ {
+		resp, err := cli.do(ctx, method, path, req.Body(), extraHeaders)
 		if err != nil {
-			return ClientErr{fmt.Errorf("failed to execute GET request: %w", err)}
+			var te TransportErr
+			if !errors.As(err, &te) {
+				return ClientErr{err}
+			}
 		}
-		return &HttpResponse{Response: resp, Codec: cli.Codec}
-	case POST:
-		var buf bytes.Buffer
-		if err := cli.Codec.EncodeRequestBody(&buf, req.Body()); err != nil {
-			return ClientErr{fmt.Errorf("failed to encode POST request body: %w", err)}
+		backoff, ok := retry.backoff(ctx, attempt, resp, err)
+		if !ok {
+			if err != nil {
+				return ClientErr{err}
+			}
+			return &HttpResponse{Response: resp, Codec: cli.responseCodec(resp)}
 		}
-		req, err := http.NewRequestWithContext(ctx, "POST", path, &buf)
-		if err != nil {
-			return ClientErr{fmt.Errorf("failed to build POST request: %w", err)}
+		if resp != nil {
+			// drain the body, to reuse the connection
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
 		}
-		req.Header = map[string][]string{
-			"Content-Type": cli.Codec.ContentType(),
+		select {
+		case <-ctx.Done():
+			return ClientErr{TransportErr{fmt.Errorf("failed to execute %s request: %w", method, ctx.Err())}}
+		case <-time.After(backoff):
 		}
-		resp, err := cli.Cli.Do(req)
-		if err != nil {
-			return ClientErr{fmt.Errorf("failed to execute POST request: %w", err)}
+	}
+}
+
+// do executes a single attempt of the request. The body is encoded for every attempt.
+func (cli *Eth2HttpClient) do(ctx context.Context, method ReqMethod, path string, body interface{}, extraHeaders Headers) (*http.Response, error) {
+	var bodyReader io.Reader
+	if method == POST {
+		var buf bytes.Buffer
+		if err := cli.Codec.EncodeRequestBody(&buf, body); err != nil {
+			return nil, fmt.Errorf("failed to encode POST request body: %w", err)
 		}
-		return &HttpResponse{Response: resp, Codec: cli.Codec}
-	default:
-		return ClientErr{fmt.Errorf("unrecognized request method enum value: %s", method)}
+		bodyReader = &buf
+	}
+	req, err := http.NewRequestWithContext(ctx, string(method), path, bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build %s request: %w", method, err)
+	}
+	req.Header = map[string][]string{
+		"Content-Type": cli.Codec.ContentType(),
+		"Accept":       cli.acceptHeader(),
+	}
+	for k, v := range extraHeaders {
+		req.Header.Set(k, v)
 	}
+	resp, err := cli.Cli.Do(req)
+	if err != nil {
+		return nil, TransportErr{fmt.Errorf("failed to execute %s request: %w", method, err)}
+	}
+	return resp, nil
 }
 
 type HttpResponse struct {
@@ -87,19 +131,68 @@ type HttpResponse struct {
 func (resp *HttpResponse) Decode(dest interface{}) (code uint, err error) {
 	hr := resp.Response
 	code = uint(hr.StatusCode)
+	PresetConsensusVersion(resp.Headers(), dest)
 	err = resp.Codec.DecodeResponseBody(code, hr.Body, dest)
 	return
 }
 
+func (resp *HttpResponse) Stream() (code uint, body io.ReadCloser, err error) {
+	hr := resp.Response
+	code = uint(hr.StatusCode)
+	if code != 200 {
+		err = resp.Codec.DecodeResponseBody(code, hr.Body, nil)
+		if err == nil {
+			err = fmt.Errorf("unexpected response status code: %d", code)
+		}
+		return code, nil, err
+	}
+	return code, hr.Body, nil
+}
+
+func (resp *HttpResponse) Headers() Headers {
+	out := make(Headers, len(resp.Response.Header))
+	for k, v := range resp.Response.Header {
+		if len(v) > 0 {
+			out[k] = v[0]
+		}
+	}
+	return out
+}
+
 type HttpRouter struct {
 	httprouter.Router
-	Codec         Codec
+	Codec Codec
+	// Codecs to negotiate response encoding with, in order of preference, see Codecs.Negotiate.
+	// Request bodies are decoded with the codec that matches their Content-Type.
+	// Optional, Codec is used if empty or if no codec matches.
+	Codecs        Codecs
 	OnEncodingErr func(error)
+	// OnPanic is called with the recovered value and the stacktrace of panics in route handlers. Optional.
+	// Panics are recovered within the HttpMiddleware, which sees the internal error response.
+	OnPanic func(req *http.Request, recovered interface{}, stack []byte)
+	// Debug mode adds the panic value and stacktrace to internal error responses of panics.
+	Debug bool
+	// Middleware wraps every route, the first middleware is the outermost.
+	// Middleware of a route group, see Group, is applied within the middleware of the router.
+	// Stream routes are not wrapped, see HttpMiddleware instead.
+	// Middleware must be set before routes are added.
+	Middleware []Middleware
+	// HttpMiddleware wraps the handler of every route and stream route, the first middleware is the outermost.
+	// HttpMiddleware is applied outside of the route Middleware.
+	// HttpMiddleware must be set before routes are added.
+	// Requests that do not match a route, i.e. unknown routes, unsupported methods and OPTIONS requests,
+	// are wrapped with the HttpMiddleware too, with an empty route.
+	HttpMiddleware []HttpMiddleware
 }
 
+// HttpMiddleware wraps the http handler of a route, given the method and the route template it serves,
+// e.g. to instrument requests per route.
+type HttpMiddleware func(method ReqMethod, route string, next http.Handler) http.Handler
+
+// NewHttpRouter creates a router that serves errors as ErrorMessage,
+// for unknown routes (404), unsupported methods (405) and panics in handlers (500).
 func NewHttpRouter() *HttpRouter {
-	// TODO: add panic and notfound handlers
-	return &HttpRouter{
+	r := &HttpRouter{
 		Router: httprouter.Router{
 			RedirectTrailingSlash:  true,
 			RedirectFixedPath:      true,
@@ -108,6 +201,59 @@ func NewHttpRouter() *HttpRouter {
 		},
 		Codec: JSONCodec{},
 	}
+	r.Router.NotFound = r.fallback(r.notFound)
+	r.Router.MethodNotAllowed = r.fallback(r.methodNotAllowed)
+	// the router sets the Allow header, CORS middleware may add to the response
+	r.Router.GlobalOPTIONS = r.fallback(func(w http.ResponseWriter, req *http.Request) {})
+	return r
+}
+
+// wrap wraps the handler with the HttpMiddleware.
+func (r *HttpRouter) wrap(method ReqMethod, route string, next http.Handler) http.Handler {
+	for i := len(r.HttpMiddleware) - 1; i >= 0; i-- {
+		next = r.HttpMiddleware[i](method, route, next)
+	}
+	return next
+}
+
+// fallback wraps the handler of requests that do not match a route with the HttpMiddleware.
+// The middleware is applied per request, since the method is only known then.
+func (r *HttpRouter) fallback(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.wrap(ReqMethod(req.Method), "", handler).ServeHTTP(w, req)
+	})
+}
+
+func (r *HttpRouter) notFound(w http.ResponseWriter, req *http.Request) {
+	r.respond(w, req, RespondNotFound(fmt.Sprintf("route not found: %s", req.URL.Path)))
+}
+
+func (r *HttpRouter) methodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	// the router sets the Allow header
+	r.respond(w, req, RespondApiError(&ErrorMessage{
+		CodeValue: http.StatusMethodNotAllowed,
+		Message:   fmt.Sprintf("method %s not allowed for route: %s", req.Method, req.URL.Path),
+	}))
+}
+
+// recoverPanic reports the panic, and responds with an internal error, unless the response was already started.
+func (r *HttpRouter) recoverPanic(w *trackingWriter, req *http.Request, recovered interface{}) {
+	if recovered == http.ErrAbortHandler {
+		panic(recovered)
+	}
+	stack := debug.Stack()
+	if r.OnPanic != nil {
+		r.OnPanic(req, recovered, stack)
+	}
+	if w.written {
+		return
+	}
+	msg := &ErrorMessage{CodeValue: http.StatusInternalServerError, Message: "internal server error"}
+	if r.Debug {
+		msg.Message = fmt.Sprintf("internal server error: panic: %v", recovered)
+		msg.Stacktraces = strings.Split(strings.TrimSpace(string(stack)), "\n")
+	}
+	r.respond(w, req, RespondApiError(msg))
 }
 
 var _ http.Handler = (*HttpRouter)(nil)
@@ -136,16 +282,174 @@ func (req httpRequest) Query(name string) (values []string, ok bool) {
 	return
 }
 
+func (req httpRequest) Header(name string) (value string, ok bool) {
+	values := req.req.Header.Values(name)
+	if len(values) == 0 {
+		return "", false
+	}
+	return values[0], true
+}
+
+// trackingWriter tracks if the response was started, to not write an error response after.
+type trackingWriter struct {
+	http.ResponseWriter
+	written bool
+}
+
+func (w *trackingWriter) WriteHeader(code int) {
+	w.written = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses.
+func (w *trackingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.written = true
+		f.Flush()
+	}
+}
+
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// handle registers the handler, wrapped with the HttpMiddleware.
+// Panics of the handler are recovered within the middleware.
+func (r *HttpRouter) handle(method ReqMethod, route string, handle httprouter.Handle) {
+	r.Router.Handler(string(method), route, r.wrap(method, route, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		tw := &trackingWriter{ResponseWriter: w}
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				r.recoverPanic(tw, req, recovered)
+			}
+		}()
+		handle(tw, req, httprouter.ParamsFromContext(req.Context()))
+	})))
+}
+
+// Use appends middleware to the router. Middleware only applies to routes added after.
+func (r *HttpRouter) Use(middleware ...Middleware) {
+	r.Middleware = append(r.Middleware, middleware...)
+}
+
+// Group creates a group of routes, that are wrapped with the given middleware, within the middleware of the router.
+func (r *HttpRouter) Group(middleware ...Middleware) *RouteGroup {
+	return &RouteGroup{Server: r, Middleware: middleware}
+}
+
 func (r *HttpRouter) AddRoute(route Route) {
-	r.Router.Handle(string(route.Method()), route.Route(),
+	route = Chain(r.Middleware...)(route)
+	r.handle(route.Method(), route.Route(),
 		func(respw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+			reqCodec, ok := r.Codecs.ByContentType(req.Header.Get("Content-Type"))
+			if !ok {
+				reqCodec = r.Codec
+			}
 			resp := route.Handle(req.Context(), httpRequest{
 				req:    req,
 				query:  req.URL.Query(),
 				params: params,
-				codec:  r.Codec,
+				codec:  reqCodec,
 			})
+			r.respond(respw, req, resp)
+		},
+	)
+}
+
+// respond writes the response, encoded with the codec negotiated with the Accept header of the request.
+func (r *HttpRouter) respond(respw http.ResponseWriter, req *http.Request, resp PreparedResponse) {
+	body := resp.Body()
+	respCodec, ok := r.Codecs.Negotiate(req.Header.Get("Accept"), body)
+	if !ok {
+		respCodec = r.Codec
+	}
+	h := respw.Header()
+	h.Set("Content-Type", respCodec.ContentType()[0])
+	for k, v := range resp.Headers() {
+		h.Add(k, v)
+	}
+	respw.WriteHeader(int(resp.Code()))
+	if err := respCodec.EncodeResponseBody(respw, body); err != nil && r.OnEncodingErr != nil {
+		r.OnEncodingErr(err)
+	}
+}
+
+// httpEventSink writes server-sent events, see https://html.spec.whatwg.org/multipage/server-sent-events.html
+type httpEventSink struct {
+	w      http.ResponseWriter
+	opened bool
+}
+
+func (s *httpEventSink) Open() error {
+	if s.opened {
+		return nil
+	}
+	s.opened = true
+	h := s.w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	s.w.WriteHeader(200)
+	return s.flush()
+}
+
+func (s *httpEventSink) flush() error {
+	f, ok := s.w.(http.Flusher)
+	if !ok {
+		return errors.New("response writer does not support flushing")
+	}
+	f.Flush()
+	return nil
+}
+
+func (s *httpEventSink) Send(ev *ServerEvent) error {
+	if err := s.Open(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ev.Data)
+	if err != nil {
+		return fmt.Errorf("failed to encode event data: %w", err)
+	}
+	var buf bytes.Buffer
+	if ev.Event != "" {
+		buf.WriteString("event: " + ev.Event + "\n")
+	}
+	if ev.ID != "" {
+		buf.WriteString("id: " + ev.ID + "\n")
+	}
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteString("\n")
+	}
+	buf.WriteString("\n")
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return s.flush()
+}
+
+var _ StreamServer = (*HttpRouter)(nil)
+
+func (r *HttpRouter) AddStreamRoute(route StreamRoute) {
+	r.handle(GET, route.Route(),
+		func(respw http.ResponseWriter, req *http.Request, params httprouter.Params) {
+			sink := &httpEventSink{w: respw}
+			resp := route.Handle(req.Context(), httpRequest{
+				req:    req,
+				query:  req.URL.Query(),
+				params: params,
+				codec:  r.Codec,
+			}, sink)
+			if sink.opened || resp == nil {
+				return
+			}
 			h := respw.Header()
+			h.Set("Content-Type", r.Codec.ContentType()[0])
 			for k, v := range resp.Headers() {
 				h.Add(k, v)
 			}
@@ -0,0 +1,387 @@
+package eth2api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/codec"
+)
+
+func TestHttpVersionedResponse(t *testing.T) {
+	spec := configs.Mainnet
+	block := &altair.SignedBeaconBlock{Message: altair.BeaconBlock{Slot: 123, ProposerIndex: 42}}
+	block.Message.Body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, spec.SYNC_COMMITTEE_SIZE/8)
+
+	router := NewHttpRouter()
+	router.Codecs = Codecs{JSONCodec{}, SSZCodec{Spec: spec}}
+	router.AddRoute(MakeRoute(GET, "/block", func(ctx context.Context, req Request) PreparedResponse {
+		return RespondOKVersioned(&VersionedSignedBeaconBlock{Version: "altair", Data: block})
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	for _, accept := range []Codecs{nil, {SSZCodec{Spec: spec}}} {
+		cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Accept: accept}
+		resp := cli.Request(context.Background(), PlainGET("/block"))
+		if v := resp.Headers()[ConsensusVersionHeader]; v != "altair" {
+			t.Fatalf("unexpected version header: %q", v)
+		}
+		var got VersionedSignedBeaconBlock
+		if _, err := resp.Decode(&got); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if got.Version != "altair" {
+			t.Fatalf("unexpected version: %q", got.Version)
+		}
+		b, ok := got.Data.(*altair.SignedBeaconBlock)
+		if !ok {
+			t.Fatalf("unexpected block type: %T", got.Data)
+		}
+		if b.Message.Slot != 123 || b.Message.ProposerIndex != 42 {
+			t.Fatalf("unexpected block: %v", b.Message)
+		}
+	}
+}
+
+func TestHttpWrappedSSZResponse(t *testing.T) {
+	spec := configs.Mainnet
+	block := &altair.SignedBeaconBlock{Message: altair.BeaconBlock{Slot: 123, ProposerIndex: 42}}
+	block.Message.Body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, spec.SYNC_COMMITTEE_SIZE/8)
+	var expected bytes.Buffer
+	if err := block.Serialize(spec, codec.NewEncodingWriter(&expected)); err != nil {
+		t.Fatal(err)
+	}
+
+	router := NewHttpRouter()
+	router.Codecs = Codecs{JSONCodec{}, SSZCodec{Spec: spec}}
+	router.AddRoute(MakeRoute(GET, "/block", func(ctx context.Context, req Request) PreparedResponse {
+		return RespondOK(Wrap(block))
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/block", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "application/octet-stream" {
+		t.Fatalf("unexpected content type: %q", ct)
+	}
+	got, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, expected.Bytes()) {
+		t.Fatal("unexpected SSZ response body")
+	}
+}
+
+func TestVersionFromHeader(t *testing.T) {
+	var got VersionedSignedBeaconBlock
+	PresetConsensusVersion(Headers{ConsensusVersionHeader: "altair"}, &got)
+	if err := got.UnmarshalJSON([]byte(`{"data": {"message": {"slot": "123"}}}`)); err != nil {
+		t.Fatalf("failed to decode without version field: %v", err)
+	}
+	if _, ok := got.Data.(*altair.SignedBeaconBlock); !ok || got.Version != "altair" {
+		t.Fatalf("unexpected block: %q %T", got.Version, got.Data)
+	}
+	// the version in the body has priority
+	if err := got.UnmarshalJSON([]byte(`{"version": "phase0", "data": {"message": {"slot": "123"}}}`)); err != nil {
+		t.Fatalf("failed to decode with version field: %v", err)
+	}
+	if got.Version != "phase0" {
+		t.Fatalf("unexpected version: %q", got.Version)
+	}
+}
+
+func TestHttpRequestHeaders(t *testing.T) {
+	router := NewHttpRouter()
+	router.AddRoute(MakeRoute(GET, "/version", func(ctx context.Context, req Request) PreparedResponse {
+		version, ok := req.Header("eth-consensus-version")
+		if !ok {
+			return RespondBadInput(errors.New("missing version header"))
+		}
+		return RespondOKMsg(version)
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/version", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(ConsensusVersionHeader, "capella")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg ErrorMessage
+	if _, err := (&HttpResponse{Response: resp, Codec: JSONCodec{}}).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Message != "capella" {
+		t.Fatalf("unexpected header value: %q", msg.Message)
+	}
+}
+
+func TestMiddlewareOrder(t *testing.T) {
+	var mu sync.Mutex
+	var trace []string
+	log := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		trace = append(trace, s)
+	}
+	named := func(name string) Middleware {
+		return func(route Route) Route {
+			return MakeRoute(route.Method(), route.Route(), func(ctx context.Context, req Request) PreparedResponse {
+				log(name + ">")
+				resp := route.Handle(ctx, req)
+				log("<" + name)
+				return resp
+			})
+		}
+	}
+	auth := func(route Route) Route {
+		return MakeRoute(route.Method(), route.Route(), func(ctx context.Context, req Request) PreparedResponse {
+			if v, _ := req.Header("Authorization"); v != "Bearer secret" {
+				return RespondApiError(&ErrorMessage{CodeValue: 401, Message: "unauthorized"})
+			}
+			return route.Handle(ctx, req)
+		})
+	}
+	handler := func(name string) HandlerFn {
+		return func(ctx context.Context, req Request) PreparedResponse {
+			log(name)
+			return RespondOKMsg(name)
+		}
+	}
+
+	router := NewHttpRouter()
+	router.HttpMiddleware = []HttpMiddleware{func(method ReqMethod, route string, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			log("http>")
+			next.ServeHTTP(w, req)
+			log("<http")
+		})
+	}}
+	router.Use(named("a"), named("b"))
+	router.AddRoute(MakeRoute(GET, "/public", handler("public")))
+	admin := router.Group(named("c"))
+	admin.Use(auth)
+	admin.Group(named("d")).AddRoute(MakeRoute(GET, "/admin", handler("admin")))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	get := func(path string, auth string) (code uint, err error) {
+		cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}}
+		mu.Lock()
+		trace = nil
+		mu.Unlock()
+		var req PreparedRequest = PlainGET(path)
+		if auth != "" {
+			req = WithHeaders(req, Headers{"Authorization": auth})
+		}
+		return cli.Request(context.Background(), req).Decode(nil)
+	}
+	for _, c := range []struct {
+		path     string
+		auth     string
+		code     uint
+		expected string
+	}{
+		{"/public", "", 200, "http> a> b> public <b <a <http"},
+		{"/admin", "Bearer secret", 200, "http> a> b> c> d> admin <d <c <b <a <http"},
+		{"/admin", "", 401, "http> a> b> c> <c <b <a <http"},
+	} {
+		code, _ := get(c.path, c.auth)
+		mu.Lock()
+		got := strings.Join(trace, " ")
+		mu.Unlock()
+		if code != c.code || got != c.expected {
+			t.Errorf("%s: got code %d, order %q, expected code %d, order %q", c.path, code, got, c.code, c.expected)
+		}
+	}
+}
+
+func TestHttpMiddlewareCORS(t *testing.T) {
+	cors := func(method ReqMethod, route string, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			h := w.Header()
+			h.Set("Access-Control-Allow-Origin", "*")
+			if req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != "" {
+				h.Set("Access-Control-Allow-Methods", h.Get("Allow"))
+				h.Set("Access-Control-Allow-Headers", "Content-Type, "+ConsensusVersionHeader)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+	router := NewHttpRouter()
+	router.HttpMiddleware = []HttpMiddleware{cors}
+	router.AddRoute(MakeRoute(POST, "/publish", func(ctx context.Context, req Request) PreparedResponse {
+		return RespondOKMsg("published")
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	request := func(method string, path string, preflight bool) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Origin", "https://example.com")
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp
+	}
+	preflight := request("OPTIONS", "/publish", true)
+	if preflight.StatusCode != http.StatusNoContent || preflight.Header.Get("Access-Control-Allow-Methods") != "OPTIONS, POST" {
+		t.Fatalf("unexpected preflight response: %d, allowed methods: %q", preflight.StatusCode, preflight.Header.Get("Access-Control-Allow-Methods"))
+	}
+	// the errors of requests that match no route are served through the middleware too
+	for _, c := range []struct {
+		method, path string
+		code         int
+	}{
+		{"POST", "/publish", 200},
+		{"GET", "/unknown", 404},
+		{"GET", "/publish", 405},
+	} {
+		resp := request(c.method, c.path, false)
+		if resp.StatusCode != c.code || resp.Header.Get("Access-Control-Allow-Origin") != "*" {
+			t.Errorf("%s %s: unexpected response %d, allowed origin: %q", c.method, c.path, resp.StatusCode, resp.Header.Get("Access-Control-Allow-Origin"))
+		}
+	}
+}
+
+func TestHttpMiddlewareBodyLimit(t *testing.T) {
+	limit := func(method ReqMethod, route string, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.Body = http.MaxBytesReader(w, req.Body, 16)
+			next.ServeHTTP(w, req)
+		})
+	}
+	router := NewHttpRouter()
+	router.HttpMiddleware = []HttpMiddleware{limit}
+	router.AddRoute(MakeRoute(POST, "/echo", func(ctx context.Context, req Request) PreparedResponse {
+		var msg string
+		if err := req.DecodeBody(&msg); err != nil {
+			return RespondBadInput(err)
+		}
+		return RespondOKMsg(msg)
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}}
+
+	var msg ErrorMessage
+	if code, err := cli.Request(context.Background(), BodyPOST("/echo", "small")).Decode(&msg); code != 200 || msg.Message != "small" {
+		t.Fatalf("unexpected response: %d %q, err: %v", code, msg.Message, err)
+	}
+	if code, err := cli.Request(context.Background(), BodyPOST("/echo", strings.Repeat("large", 10))).Decode(nil); code != 400 {
+		t.Fatalf("expected bad input for large body, got %d, err: %v", code, err)
+	}
+}
+
+func TestHttpRouterErrors(t *testing.T) {
+	var panics []interface{}
+	var codes []int
+	router := NewHttpRouter()
+	router.OnPanic = func(req *http.Request, recovered interface{}, stack []byte) {
+		panics = append(panics, recovered)
+	}
+	// the middleware completes, and sees the error responses
+	router.HttpMiddleware = []HttpMiddleware{func(method ReqMethod, route string, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			rec := httptest.NewRecorder()
+			next.ServeHTTP(rec, req)
+			codes = append(codes, rec.Code)
+			for k, v := range rec.Header() {
+				w.Header()[k] = v
+			}
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(rec.Body.Bytes())
+		})
+	}}
+	router.AddRoute(MakeRoute(GET, "/panic", func(ctx context.Context, req Request) PreparedResponse {
+		panic("oops")
+	}))
+	router.AddStreamRoute(MakeStreamRoute("/stream", func(ctx context.Context, req Request, sink EventSink) PreparedResponse {
+		if err := sink.Open(); err != nil {
+			return RespondInternalError(err)
+		}
+		panic("stream oops")
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}}
+
+	request := func(req PreparedRequest) (*ErrorMessage, Headers) {
+		resp := cli.Request(context.Background(), req)
+		_, err := resp.Decode(nil)
+		var msg *ErrorMessage
+		if !errors.As(err, &msg) {
+			t.Fatalf("expected error message, got: %v", err)
+		}
+		return msg, resp.Headers()
+	}
+
+	if msg, _ := request(PlainGET("/unknown")); msg.CodeValue != 404 || msg.Message != "route not found: /unknown" {
+		t.Errorf("unexpected not found error: %v", msg)
+	}
+	if msg, h := request(BodyPOST("/panic", nil)); msg.CodeValue != 405 || h["Allow"] != "GET, OPTIONS" {
+		t.Errorf("unexpected method not allowed error: %v, allow: %q", msg, h["Allow"])
+	}
+	if msg, _ := request(PlainGET("/panic")); msg.CodeValue != 500 || msg.Message != "internal server error" || len(msg.Stacktraces) != 0 {
+		t.Errorf("unexpected panic error: %v", msg)
+	}
+	router.Debug = true
+	msg, _ := request(PlainGET("/panic"))
+	if msg.CodeValue != 500 || msg.Message != "internal server error: panic: oops" {
+		t.Errorf("unexpected debug panic error: %v", msg)
+	}
+	if !strings.Contains(strings.Join(msg.Stacktraces, "\n"), "TestHttpRouterErrors") {
+		t.Errorf("expected stacktrace of the panic, got: %v", msg.Stacktraces)
+	}
+	if len(panics) != 2 || panics[0] != "oops" {
+		t.Errorf("expected panics to be reported, got: %v", panics)
+	}
+	if fmt.Sprint(codes) != "[404 405 500 500]" {
+		t.Errorf("unexpected codes seen by the middleware: %v", codes)
+	}
+
+	// no error response is written after the stream started
+	resp, err := srv.Client().Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode != 200 || len(body) != 0 || len(panics) != 3 || panics[2] != "stream oops" {
+		t.Errorf("unexpected stream response: %d %q, panics: %v", resp.StatusCode, body, panics)
+	}
+}
@@ -0,0 +1,128 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Client is an eth2api.Client decorator that records request metrics, prefixed with "eth2api_client_".
+//
+// Requests are measured until the response is decoded, or until the body of a streamed response is closed.
+// Requests without response, e.g. due to a transport error, are counted with status code 0.
+// Responses of the Eth2HttpClient are passed through as *eth2api.HttpResponse, so they can be decorated further.
+type Client struct {
+	Client eth2api.Client
+	// Templates to label requests with, DefaultTemplates if nil.
+	Templates *Templates
+
+	metrics *requestMetrics
+}
+
+var _ eth2api.Client = (*Client)(nil)
+
+func NewClient(cli eth2api.Client, reg Registry) *Client {
+	return &Client{Client: cli, metrics: newRequestMetrics(reg, "eth2api_client_")}
+}
+
+func (c *Client) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	templates := c.Templates
+	if templates == nil {
+		templates = DefaultTemplates
+	}
+	route, ok := templates.Match(req.Path())
+	if !ok {
+		route = UnknownRoute
+	}
+	o := &observation{m: c.metrics, method: string(req.Method()), route: route, start: time.Now()}
+	resp := c.Client.Request(ctx, req)
+	if hr, ok := resp.(*eth2api.HttpResponse); ok {
+		if r := hr.Request; r != nil && r.ContentLength > 0 {
+			o.requestSize = r.ContentLength
+		}
+		o.code = uint(hr.StatusCode)
+		hr.Body = &countingBody{ReadCloser: hr.Body, o: o}
+		return hr
+	}
+	return &response{Response: resp, o: o}
+}
+
+// observation of a single request, recorded once.
+type observation struct {
+	m           *requestMetrics
+	method      string
+	route       string
+	start       time.Time
+	code        uint
+	requestSize int64
+	once        sync.Once
+}
+
+func (o *observation) record(code uint, responseSize int64) {
+	o.once.Do(func() {
+		o.m.requests.Add(1, o.method, o.route, strconv.FormatUint(uint64(code), 10))
+		o.m.duration.Observe(time.Since(o.start).Seconds(), o.method, o.route)
+		if o.requestSize > 0 {
+			o.m.requestSize.Observe(float64(o.requestSize), o.method, o.route)
+		}
+		if code != 0 {
+			o.m.responseSize.Observe(float64(responseSize), o.method, o.route)
+		}
+	})
+}
+
+// countingBody records the request when the response body is closed.
+type countingBody struct {
+	io.ReadCloser
+	o *observation
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (n int, err error) {
+	n, err = b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.o.record(b.o.code, b.n)
+	return err
+}
+
+// response records the request when it is decoded, the response size is unknown.
+type response struct {
+	eth2api.Response
+	o *observation
+}
+
+var _ eth2api.StreamResponse = (*response)(nil)
+
+func (r *response) Decode(dest interface{}) (code uint, err error) {
+	code, err = r.Response.Decode(dest)
+	r.o.record(code, 0)
+	return code, err
+}
+
+func (r *response) Stream() (code uint, body io.ReadCloser, err error) {
+	sr, ok := r.Response.(eth2api.StreamResponse)
+	if !ok {
+		code, err = r.Decode(nil)
+		if err == nil {
+			err = fmt.Errorf("client response type %T does not support streaming", r.Response)
+		}
+		return code, nil, err
+	}
+	code, body, err = sr.Stream()
+	if err != nil || body == nil {
+		r.o.record(code, 0)
+		return code, body, err
+	}
+	r.o.code = code
+	return code, &countingBody{ReadCloser: body, o: r.o}, nil
+}
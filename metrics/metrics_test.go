@@ -0,0 +1,125 @@
+package metrics
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+func TestTemplates(t *testing.T) {
+	templates := NewTemplates(
+		"/eth/v1/beacon/states/:stateId/validators",
+		"/eth/v1/beacon/states/:stateId/validators/:validatorId",
+		"/eth/v1/beacon/headers/:blockId",
+		"/eth/v1/beacon/headers/head",
+		"/eth/v1/node/*path",
+	)
+	for path, expected := range map[string]string{
+		"/eth/v1/beacon/states/head/validators":       "/eth/v1/beacon/states/:stateId/validators",
+		"/eth/v1/beacon/states/123/validators?id=1,2": "/eth/v1/beacon/states/:stateId/validators",
+		"/eth/v1/beacon/states/0x01/validators/42":    "/eth/v1/beacon/states/:stateId/validators/:validatorId",
+		"/eth/v1/beacon/headers/head":                 "/eth/v1/beacon/headers/head",
+		"/eth/v1/beacon/headers/finalized":            "/eth/v1/beacon/headers/:blockId",
+		"/eth/v1/node/peers/abc":                      "/eth/v1/node/*path",
+		"/eth/v1/beacon/states/head":                  "",
+		"/eth/v1/config/spec":                         "",
+	} {
+		got, ok := templates.Match(path)
+		if got != expected || ok != (expected != "") {
+			t.Errorf("path %s: got template %q, expected %q", path, got, expected)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	serverReg := NewMemoryRegistry()
+	router := eth2api.NewHttpRouter()
+	router.HttpMiddleware = append(router.HttpMiddleware, NewServer(serverReg).Middleware)
+	router.AddRoute(eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/root",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			if req.Param("stateId") == "finalized" {
+				return eth2api.RespondNotFound("unknown state")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&eth2api.RootResponse{Root: common.Root{1}}))
+		}))
+	router.AddRoute(eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/voluntary_exits",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var exit phase0.SignedVoluntaryExit
+			if err := req.DecodeBody(&exit); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			return eth2api.RespondOK(nil)
+		}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	clientReg := NewMemoryRegistry()
+	cli := NewClient(&eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}, clientReg)
+	ctx := context.Background()
+
+	for _, id := range []eth2api.StateId{eth2api.StateHead, eth2api.StateIdSlot(12)} {
+		if root, exists, err := beaconapi.StateRoot(ctx, cli, id); err != nil || !exists || root != (common.Root{1}) {
+			t.Fatalf("unexpected state root: %s, exists: %v, err: %v", root, exists, err)
+		}
+	}
+	if _, exists, err := beaconapi.StateRoot(ctx, cli, eth2api.StateFinalized); err != nil || exists {
+		t.Fatalf("expected unknown state, exists: %v, err: %v", exists, err)
+	}
+	exit := &phase0.SignedVoluntaryExit{Message: phase0.VoluntaryExit{Epoch: 1, ValidatorIndex: 2}}
+	if err := beaconapi.SubmitVoluntaryExit(ctx, cli, exit); err != nil {
+		t.Fatal(err)
+	}
+
+	const root = "/eth/v1/beacon/states/:stateId/root"
+	const exits = "/eth/v1/beacon/pool/voluntary_exits"
+	for _, side := range []struct {
+		reg    *MemoryRegistry
+		prefix string
+	}{{clientReg, "eth2api_client_"}, {serverReg, "eth2api_server_"}} {
+		if v := side.reg.Value(side.prefix+"requests_total", "GET", root, "200"); v != 2 {
+			t.Errorf("%s: expected 2 OK state root requests, got %v", side.prefix, v)
+		}
+		if v := side.reg.Value(side.prefix+"requests_total", "GET", root, "404"); v != 1 {
+			t.Errorf("%s: expected 1 unknown state root request, got %v", side.prefix, v)
+		}
+		if v := side.reg.Value(side.prefix+"requests_total", "POST", exits, "200"); v != 1 {
+			t.Errorf("%s: expected 1 exit request, got %v", side.prefix, v)
+		}
+		if c := side.reg.Count(side.prefix+"request_duration_seconds", "GET", root); c != 3 {
+			t.Errorf("%s: expected 3 state root latencies, got %v", side.prefix, c)
+		}
+		if c := side.reg.Count(side.prefix+"request_size_bytes", "POST", exits); c != 1 {
+			t.Errorf("%s: expected exit request size, got %d observations", side.prefix, c)
+		}
+		if c := side.reg.Count(side.prefix+"request_size_bytes", "GET", root); c != 0 {
+			t.Errorf("%s: expected no GET request sizes, got %d observations", side.prefix, c)
+		}
+		if v := side.reg.Value(side.prefix+"response_size_bytes", "GET", root); v == 0 {
+			t.Errorf("%s: expected state root response sizes", side.prefix)
+		}
+	}
+	if clientReg.Value("eth2api_client_request_size_bytes", "POST", exits) != serverReg.Value("eth2api_server_request_size_bytes", "POST", exits) {
+		t.Errorf("expected client and server to agree on the request size")
+	}
+
+	// requests without response are counted with code 0, unknown paths are not labelled by path
+	unreachable := NewClient(&eth2api.Eth2HttpClient{Addr: "http://127.0.0.1:0", Cli: srv.Client(), Codec: eth2api.JSONCodec{}}, clientReg)
+	if _, err := unreachable.Request(ctx, eth2api.PlainGET("/foo/bar")).Decode(nil); err == nil {
+		t.Fatal("expected transport error")
+	}
+	if v := clientReg.Value("eth2api_client_requests_total", "GET", UnknownRoute, "0"); v != 1 {
+		t.Errorf("expected failed request to be counted, got %v", v)
+	}
+	// requests to unknown routes of the server are counted too
+	if _, err := cli.Request(ctx, eth2api.PlainGET("/foo/bar")).Decode(nil); err == nil {
+		t.Fatal("expected not found error")
+	}
+	if v := serverReg.Value("eth2api_server_requests_total", "GET", UnknownRoute, "404"); v != 1 {
+		t.Errorf("expected unknown route request to be counted, got %v", v)
+	}
+}
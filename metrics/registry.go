@@ -0,0 +1,130 @@
+// Package metrics instruments eth2api clients and HTTP routers with request metrics:
+// counts, latencies, status codes and body sizes, labelled per route template.
+//
+// Metrics are exported through the Registry interface, to be implemented with any metrics library.
+// E.g. with Prometheus, a Counter wraps a CounterVec, and a Histogram wraps a HistogramVec.
+package metrics
+
+import "sync"
+
+// Registry creates metrics with the given labels. Label values are passed in the same order as the labels.
+type Registry interface {
+	Counter(name string, help string, labels ...string) Counter
+	Histogram(name string, help string, buckets []float64, labels ...string) Histogram
+}
+
+type Counter interface {
+	Add(v float64, labelValues ...string)
+}
+
+type Histogram interface {
+	Observe(v float64, labelValues ...string)
+}
+
+// Label names of the request metrics.
+const (
+	LabelMethod = "method"
+	LabelRoute  = "route"
+	LabelCode   = "code"
+)
+
+// UnknownRoute labels requests of clients that do not match any route template,
+// and requests to servers that do not match any route.
+const UnknownRoute = "unknown"
+
+var (
+	// DefaultLatencyBuckets, in seconds.
+	DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	// DefaultSizeBuckets, in bytes, up to the size of large beacon states.
+	DefaultSizeBuckets = []float64{256, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10, 1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20}
+)
+
+// requestMetrics are the metrics shared by clients and servers, with a name prefix per side.
+type requestMetrics struct {
+	requests     Counter
+	duration     Histogram
+	requestSize  Histogram
+	responseSize Histogram
+}
+
+func newRequestMetrics(reg Registry, prefix string) *requestMetrics {
+	return &requestMetrics{
+		requests: reg.Counter(prefix+"requests_total",
+			"Number of requests, by method, route and status code.",
+			LabelMethod, LabelRoute, LabelCode),
+		duration: reg.Histogram(prefix+"request_duration_seconds",
+			"Duration of requests in seconds, including the response body, by method and route.",
+			DefaultLatencyBuckets, LabelMethod, LabelRoute),
+		requestSize: reg.Histogram(prefix+"request_size_bytes",
+			"Size of request bodies in bytes, by method and route.",
+			DefaultSizeBuckets, LabelMethod, LabelRoute),
+		responseSize: reg.Histogram(prefix+"response_size_bytes",
+			"Size of response bodies in bytes, by method and route.",
+			DefaultSizeBuckets, LabelMethod, LabelRoute),
+	}
+}
+
+// MemoryRegistry keeps metrics in memory, e.g. for tests or debugging.
+// Histograms keep the sum and count of the observed values, not the buckets.
+type MemoryRegistry struct {
+	mu     sync.Mutex
+	values map[string]float64
+	counts map[string]uint64
+}
+
+var _ Registry = (*MemoryRegistry)(nil)
+
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{values: make(map[string]float64), counts: make(map[string]uint64)}
+}
+
+func memoryKey(name string, labelValues []string) string {
+	key := name
+	for _, v := range labelValues {
+		key += "/" + v
+	}
+	return key
+}
+
+func (m *MemoryRegistry) add(name string, v float64, labelValues []string) {
+	key := memoryKey(name, labelValues)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] += v
+	m.counts[key]++
+}
+
+// Value returns the total of a counter, or the sum of the observations of a histogram.
+func (m *MemoryRegistry) Value(name string, labelValues ...string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.values[memoryKey(name, labelValues)]
+}
+
+// Count returns the number of additions to a counter, or the number of observations of a histogram.
+func (m *MemoryRegistry) Count(name string, labelValues ...string) uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[memoryKey(name, labelValues)]
+}
+
+func (m *MemoryRegistry) Counter(name string, help string, labels ...string) Counter {
+	return memoryMetric{m, name}
+}
+
+func (m *MemoryRegistry) Histogram(name string, help string, buckets []float64, labels ...string) Histogram {
+	return memoryMetric{m, name}
+}
+
+type memoryMetric struct {
+	m    *MemoryRegistry
+	name string
+}
+
+func (mm memoryMetric) Add(v float64, labelValues ...string) {
+	mm.m.add(mm.name, v, labelValues)
+}
+
+func (mm memoryMetric) Observe(v float64, labelValues ...string) {
+	mm.m.add(mm.name, v, labelValues)
+}
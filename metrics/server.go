@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Server records request metrics of an HttpRouter, prefixed with "eth2api_server_".
+// Requests are labelled with the route template they are served by, e.g. "/eth/v1/beacon/states/:stateId/root",
+// or with UnknownRoute if they do not match any route.
+//
+// To instrument all routes, add the middleware before adding the routes:
+//
+//	router.HttpMiddleware = append(router.HttpMiddleware, metrics.NewServer(reg).Middleware)
+type Server struct {
+	metrics *requestMetrics
+}
+
+func NewServer(reg Registry) *Server {
+	return &Server{metrics: newRequestMetrics(reg, "eth2api_server_")}
+}
+
+var _ eth2api.HttpMiddleware = (*Server)(nil).Middleware
+
+func (s *Server) Middleware(method eth2api.ReqMethod, route string, next http.Handler) http.Handler {
+	m := string(method)
+	if route == "" {
+		route = UnknownRoute
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		body := &countingReader{ReadCloser: req.Body}
+		req.Body = body
+		rw := &responseWriter{ResponseWriter: w}
+		next.ServeHTTP(rw, req)
+		code := rw.code
+		if code == 0 {
+			code = http.StatusOK
+		}
+		s.metrics.requests.Add(1, m, route, strconv.Itoa(code))
+		s.metrics.duration.Observe(time.Since(start).Seconds(), m, route)
+		// the handler may not read the body in full, prefer the declared size
+		if size := max(req.ContentLength, body.n); size > 0 {
+			s.metrics.requestSize.Observe(float64(size), m, route)
+		}
+		s.metrics.responseSize.Observe(float64(rw.n), m, route)
+	})
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// responseWriter captures the status code and counts the response body size.
+// It supports flushing, for event streams.
+type responseWriter struct {
+	http.ResponseWriter
+	code int
+	n    int64
+}
+
+func (w *responseWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,154 @@
+package metrics
+
+import "strings"
+
+// DefaultTemplates are the route templates of the standard beacon API, to label client requests with.
+var DefaultTemplates = NewTemplates(
+	"/eth/v1/beacon/genesis",
+	"/eth/v1/beacon/states/:stateId/root",
+	"/eth/v1/beacon/states/:stateId/fork",
+	"/eth/v1/beacon/states/:stateId/finality_checkpoints",
+	"/eth/v1/beacon/states/:stateId/validators",
+	"/eth/v1/beacon/states/:stateId/validators/:validatorId",
+	"/eth/v1/beacon/states/:stateId/validator_balances",
+	"/eth/v1/beacon/states/:stateId/committees",
+	"/eth/v1/beacon/states/:stateId/sync_committees",
+	"/eth/v1/beacon/states/:stateId/randao",
+	"/eth/v1/beacon/headers",
+	"/eth/v1/beacon/headers/:blockId",
+	"/eth/v1/beacon/blinded_blocks",
+	"/eth/v2/beacon/blinded_blocks",
+	"/eth/v1/beacon/blinded_blocks/:blockId",
+	"/eth/v1/beacon/blocks",
+	"/eth/v2/beacon/blocks",
+	"/eth/v1/beacon/blocks/:blockId",
+	"/eth/v2/beacon/blocks/:blockId",
+	"/eth/v1/beacon/blocks/:blockId/root",
+	"/eth/v1/beacon/blocks/:blockId/attestations",
+	"/eth/v1/beacon/blob_sidecars/:blockId",
+	"/eth/v1/beacon/pool/attestations",
+	"/eth/v1/beacon/pool/attester_slashings",
+	"/eth/v1/beacon/pool/proposer_slashings",
+	"/eth/v1/beacon/pool/sync_committees",
+	"/eth/v1/beacon/pool/voluntary_exits",
+	"/eth/v1/beacon/pool/bls_to_execution_changes",
+	"/eth/v1/builder/states/:stateId/expected_withdrawals",
+	"/eth/v1/config/fork_schedule",
+	"/eth/v1/config/spec",
+	"/eth/v1/config/deposit_contract",
+	"/eth/v1/debug/beacon/states/:stateId",
+	"/eth/v2/debug/beacon/states/:stateId",
+	"/eth/v1/debug/beacon/heads",
+	"/eth/v2/debug/beacon/heads",
+	"/eth/v1/events",
+	"/eth/v1/node/identity",
+	"/eth/v1/node/peers",
+	"/eth/v1/node/peers/:peerId",
+	"/eth/v1/node/peer_count",
+	"/eth/v1/node/version",
+	"/eth/v1/node/syncing",
+	"/eth/v1/node/health",
+	"/eth/v1/validator/duties/attester/:epoch",
+	"/eth/v1/validator/duties/proposer/:epoch",
+	"/eth/v1/validator/duties/sync/:epoch",
+	"/eth/v1/validator/blocks/:slot",
+	"/eth/v2/validator/blocks/:slot",
+	"/eth/v3/validator/blocks/:slot",
+	"/eth/v1/validator/blinded_blocks/:slot",
+	"/eth/v1/validator/attestation_data",
+	"/eth/v1/validator/aggregate_attestation",
+	"/eth/v1/validator/aggregate_and_proofs",
+	"/eth/v1/validator/beacon_committee_subscriptions",
+	"/eth/v1/validator/sync_committee_subscriptions",
+	"/eth/v1/validator/sync_committee_contribution",
+	"/eth/v1/validator/contribution_and_proofs",
+	"/eth/v1/validator/prepare_beacon_proposer",
+	"/eth/v1/validator/register_validator",
+)
+
+// Templates matches request paths to route templates, in the format of the HttpRouter:
+// ":name" matches a single path segment, "*name" matches the remainder of the path.
+// Static segments take precedence over parameters.
+type Templates struct {
+	root templateNode
+}
+
+type templateNode struct {
+	static   map[string]*templateNode
+	param    *templateNode
+	catchAll string
+	route    string
+}
+
+func NewTemplates(routes ...string) *Templates {
+	t := &Templates{}
+	for _, r := range routes {
+		t.Add(r)
+	}
+	return t
+}
+
+// Add a route template. Not safe for concurrent use with Match.
+func (t *Templates) Add(route string) {
+	n := &t.root
+	for _, seg := range segments(route) {
+		if strings.HasPrefix(seg, "*") {
+			n.catchAll = route
+			return
+		}
+		if strings.HasPrefix(seg, ":") {
+			if n.param == nil {
+				n.param = &templateNode{}
+			}
+			n = n.param
+			continue
+		}
+		if n.static == nil {
+			n.static = make(map[string]*templateNode)
+		}
+		next, ok := n.static[seg]
+		if !ok {
+			next = &templateNode{}
+			n.static[seg] = next
+		}
+		n = next
+	}
+	n.route = route
+}
+
+// Match returns the template of the path, the query is ignored.
+func (t *Templates) Match(path string) (route string, ok bool) {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	route = t.root.match(segments(path))
+	return route, route != ""
+}
+
+func (n *templateNode) match(segs []string) string {
+	if len(segs) == 0 {
+		if n.route != "" {
+			return n.route
+		}
+		return n.catchAll
+	}
+	if next, ok := n.static[segs[0]]; ok {
+		if r := next.match(segs[1:]); r != "" {
+			return r
+		}
+	}
+	if n.param != nil {
+		if r := n.param.match(segs[1:]); r != "" {
+			return r
+		}
+	}
+	return n.catchAll
+}
+
+func segments(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
@@ -0,0 +1,125 @@
+package eth2api
+
+import (
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// SelectiveCodec is an optional Codec extension, for codecs that can only encode some types of data.
+// Codecs without this extension are assumed to support everything.
+type SelectiveCodec interface {
+	Codec
+	// Supports checks if the data can be encoded with this codec.
+	Supports(data interface{}) bool
+}
+
+func codecSupports(c Codec, data interface{}) bool {
+	if sc, ok := c.(SelectiveCodec); ok {
+		return sc.Supports(data)
+	}
+	return true
+}
+
+// Codecs is a list of codecs, in order of preference, to negotiate content-types with.
+type Codecs []Codec
+
+// ByContentType finds the codec for the given Content-Type header value.
+// Media-type parameters, like the charset, are ignored.
+func (cs Codecs) ByContentType(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	for _, c := range cs {
+		for _, ct := range c.ContentType() {
+			if strings.EqualFold(ct, mediaType) {
+				return c, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// Accept formats an Accept header value, listing the content-types of all codecs,
+// with decreasing quality values to express the order of preference.
+func (cs Codecs) Accept() string {
+	var out strings.Builder
+	q := 10
+	for _, c := range cs {
+		for _, ct := range c.ContentType() {
+			if out.Len() > 0 {
+				out.WriteString(", ")
+			}
+			out.WriteString(ct)
+			if q < 10 {
+				_, _ = fmt.Fprintf(&out, ";q=0.%d", q)
+			}
+		}
+		if q > 1 {
+			q--
+		}
+	}
+	return out.String()
+}
+
+type acceptRange struct {
+	mediaType string
+	q         float64
+}
+
+func (ar *acceptRange) matches(contentType string) bool {
+	if ar.mediaType == "*/*" {
+		return true
+	}
+	if strings.HasSuffix(ar.mediaType, "/*") {
+		return strings.HasPrefix(strings.ToLower(contentType), strings.TrimSuffix(ar.mediaType, "*"))
+	}
+	return strings.EqualFold(ar.mediaType, contentType)
+}
+
+func parseAccept(accept string) (out []acceptRange) {
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+		out = append(out, acceptRange{mediaType: mediaType, q: q})
+	}
+	return out
+}
+
+// Negotiate picks the codec to encode the data with, based on the given Accept header value.
+// Codecs that do not support the data are skipped.
+// The codec with the highest quality value is selected, ties are resolved by the codecs order of preference.
+// If the Accept header is empty, the first codec that supports the data is selected.
+func (cs Codecs) Negotiate(accept string, data interface{}) (Codec, bool) {
+	ranges := parseAccept(accept)
+	var best Codec
+	bestQ := 0.0
+	for _, c := range cs {
+		if !codecSupports(c, data) {
+			continue
+		}
+		if len(ranges) == 0 {
+			return c, true
+		}
+		for _, ct := range c.ContentType() {
+			for i := range ranges {
+				if ranges[i].q > bestQ && ranges[i].matches(ct) {
+					best = c
+					bestQ = ranges[i].q
+				}
+			}
+		}
+	}
+	return best, best != nil
+}
@@ -0,0 +1,88 @@
+package eth2api
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/zrnt/eth2/configs"
+)
+
+func TestCodecsNegotiate(t *testing.T) {
+	ssz := SSZCodec{Spec: configs.Mainnet}
+	codecs := Codecs{JSONCodec{}, ssz}
+	block := new(phase0.SignedBeaconBlock)
+	cases := []struct {
+		accept string
+		data   interface{}
+		expect Codec
+	}{
+		{"", block, JSONCodec{}},
+		{"application/octet-stream", block, ssz},
+		{"application/octet-stream;q=0.9, application/json", block, JSONCodec{}},
+		{"application/octet-stream, application/json;q=0.9", block, ssz},
+		{"application/octet-stream", &ErrorMessage{}, nil},
+		{"application/octet-stream, */*;q=0.1", &ErrorMessage{}, JSONCodec{}},
+		{"text/html", block, nil},
+	}
+	for i, c := range cases {
+		got, ok := codecs.Negotiate(c.accept, c.data)
+		if c.expect == nil {
+			if ok {
+				t.Errorf("case %d: expected no codec, got %T", i, got)
+			}
+			continue
+		}
+		if !ok || got != c.expect {
+			t.Errorf("case %d: expected %T, got %T", i, c.expect, got)
+		}
+	}
+}
+
+func TestCodecsAccept(t *testing.T) {
+	codecs := Codecs{SSZCodec{}, JSONCodec{}}
+	if got, expected := codecs.Accept(), "application/octet-stream, application/json;q=0.9"; got != expected {
+		t.Fatalf("got %q, expected %q", got, expected)
+	}
+}
+
+func TestHttpContentNegotiation(t *testing.T) {
+	spec := configs.Mainnet
+	block := &phase0.SignedBeaconBlock{Message: phase0.BeaconBlock{Slot: 123, ProposerIndex: 42}}
+
+	router := NewHttpRouter()
+	router.Codecs = Codecs{JSONCodec{}, SSZCodec{Spec: spec}}
+	router.AddRoute(MakeRoute(GET, "/block", func(ctx context.Context, req Request) PreparedResponse {
+		return RespondOK(block)
+	}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	for _, accept := range []Codecs{nil, {SSZCodec{Spec: spec}, JSONCodec{}}} {
+		cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Accept: accept}
+		resp := cli.Request(context.Background(), PlainGET("/block"))
+		contentType := resp.(*HttpResponse).Header.Get("Content-Type")
+		if len(accept) > 0 && contentType != "application/octet-stream" {
+			t.Fatalf("expected SSZ response, got %q", contentType)
+		}
+		var got phase0.SignedBeaconBlock
+		if _, err := resp.Decode(&got); err != nil {
+			t.Fatalf("failed to decode %q response: %v", contentType, err)
+		}
+		if got.Message.Slot != 123 || got.Message.ProposerIndex != 42 {
+			t.Fatalf("unexpected %q response block: %v", contentType, got.Message)
+		}
+	}
+
+	// error messages do not have an SSZ representation, and fall back to JSON
+	router.AddRoute(MakeRoute(GET, "/missing", func(ctx context.Context, req Request) PreparedResponse {
+		return RespondNotFound("nothing here")
+	}))
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Accept: Codecs{SSZCodec{Spec: spec}}}
+	if _, err := cli.Request(context.Background(), PlainGET("/missing")).Decode(new(phase0.SignedBeaconBlock)); err == nil {
+		t.Fatal("expected error")
+	} else if apiErr, ok := err.(ApiError); !ok || apiErr.Code() != 404 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -1,10 +1,12 @@
 package eth2api
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/ztyp/view"
 )
 
 type ValidatorId interface {
@@ -160,3 +162,80 @@ func (sf StatusFilter) String() string {
 	}
 	return out.String()
 }
+
+// BroadcastValidation is the validation a published block must pass, before the node broadcasts it.
+type BroadcastValidation string
+
+const (
+	// Lightweight gossip checks only.
+	BroadcastValidationGossip BroadcastValidation = "gossip"
+	// Full consensus checks, including validation of all signatures and state transition.
+	BroadcastValidationConsensus BroadcastValidation = "consensus"
+	// Full consensus checks, and a check that the block is not an equivocation.
+	BroadcastValidationConsensusAndEquivocation BroadcastValidation = "consensus_and_equivocation"
+)
+
+func (bv BroadcastValidation) String() string {
+	return string(bv)
+}
+
+func ParseBroadcastValidation(v string) (BroadcastValidation, error) {
+	switch bv := BroadcastValidation(v); bv {
+	case BroadcastValidationGossip, BroadcastValidationConsensus, BroadcastValidationConsensusAndEquivocation:
+		return bv, nil
+	default:
+		return "", fmt.Errorf("unknown broadcast validation: %q", v)
+	}
+}
+
+type BlobIndexFilter []view.Uint64View
+
+func (bf BlobIndexFilter) String() string {
+	var out strings.Builder
+	for i := range bf {
+		out.WriteString(strconv.FormatUint(uint64(bf[i]), 10))
+		if i+1 < len(bf) {
+			out.WriteRune(',')
+		}
+	}
+	return out.String()
+}
+
+type EventTopicFilter []EventTopic
+
+func (tf EventTopicFilter) String() string {
+	var out strings.Builder
+	for i := range tf {
+		out.WriteString(string(tf[i]))
+		if i+1 < len(tf) {
+			out.WriteRune(',')
+		}
+	}
+	return out.String()
+}
+
+type ConnectionStateFilter []ConnectionState
+
+func (sf ConnectionStateFilter) String() string {
+	var out strings.Builder
+	for i := range sf {
+		out.WriteString(string(sf[i]))
+		if i+1 < len(sf) {
+			out.WriteRune(',')
+		}
+	}
+	return out.String()
+}
+
+type ConnectionDirectionFilter []ConnectionDirection
+
+func (df ConnectionDirectionFilter) String() string {
+	var out strings.Builder
+	for i := range df {
+		out.WriteString(string(df[i]))
+		if i+1 < len(df) {
+			out.WriteRune(',')
+		}
+	}
+	return out.String()
+}
@@ -74,6 +74,25 @@ func RespondOK(body interface{}) PreparedResponse {
 	}
 }
 
+// RespondOKVersioned responds with a versioned object, and specifies the version in the Eth-Consensus-Version header.
+func RespondOKVersioned(body ConsensusVersioned) PreparedResponse {
+	return &BasicResponse{
+		code:    200,
+		body:    body,
+		headers: Headers{ConsensusVersionHeader: body.ConsensusVersion()},
+	}
+}
+
+// RespondOKProducedBlock responds with a block of produce block v3, and specifies the version,
+// whether the block is blinded, and the values of the block in the response headers.
+func RespondOKProducedBlock(block *ProducedBlock) PreparedResponse {
+	return &BasicResponse{
+		code:    200,
+		body:    block,
+		headers: block.Headers(),
+	}
+}
+
 func RespondOKMsg(msg string) PreparedResponse {
 	return &BasicResponse{
 		code: 200,
@@ -104,6 +123,32 @@ func RespondSyncing(msg string) PreparedResponse {
 	}
 }
 
+// RespondNotImplemented responds with a 501 error, for routes that the backend does not support.
+func RespondNotImplemented(msg string) PreparedResponse {
+	return &BasicResponse{
+		code: 501,
+		body: &ErrorMessage{
+			CodeValue: 501,
+			Message:   msg,
+		},
+	}
+}
+
+// RespondApiError responds with the error, using the status code of the error.
+func RespondApiError(err ApiError) PreparedResponse {
+	msg, ok := err.(*ErrorMessage)
+	if !ok {
+		msg = &ErrorMessage{
+			CodeValue: err.Code(),
+			Message:   err.Error(),
+		}
+	}
+	return &BasicResponse{
+		code: err.Code(),
+		body: msg,
+	}
+}
+
 type ApiError interface {
 	error
 	Code() uint
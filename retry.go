@@ -0,0 +1,139 @@
+package eth2api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetrySafeRequest is an optional PreparedRequest extension, for POST requests that are safe to retry.
+// GET requests are always considered safe to retry.
+type RetrySafeRequest interface {
+	PreparedRequest
+	// RetrySafe returns true if the request is idempotent, and may be sent more than once.
+	RetrySafe() bool
+}
+
+type retrySafeReq struct {
+	PreparedRequest
+}
+
+func (r *retrySafeReq) RetrySafe() bool {
+	return true
+}
+
+func (r *retrySafeReq) Headers() Headers {
+	if hr, ok := r.PreparedRequest.(HeadersRequest); ok {
+		return hr.Headers()
+	}
+	return nil
+}
+
+// WithRetrySafe marks the request as safe to retry, e.g. an idempotent POST request to publish a block.
+func WithRetrySafe(req PreparedRequest) RetrySafeRequest {
+	return &retrySafeReq{PreparedRequest: req}
+}
+
+func isRetrySafe(req PreparedRequest) bool {
+	if req.Method() == GET {
+		return true
+	}
+	rs, ok := req.(RetrySafeRequest)
+	return ok && rs.RetrySafe()
+}
+
+// RetryPolicy decides when and how soon a failed request is retried by the Eth2HttpClient.
+//
+// Transport errors, and responses with a status code in RetryStatus, are retried
+// with exponential backoff and jitter, until MaxAttempts is reached.
+// A Retry-After header in the response is respected, e.g. on a 429 Too Many Requests response,
+// but the request is not retried if the node asks to wait longer than MaxBackoff.
+// No retry is attempted if it cannot happen before the deadline of the request context.
+type RetryPolicy struct {
+	// MaxAttempts of the request, including the first attempt.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled for every retry after that.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+	// Jitter is the fraction, between 0 and 1, of the backoff that is randomized,
+	// to avoid synchronized retries of many clients.
+	Jitter float64
+	// RetryStatus lists the response status codes to retry.
+	RetryStatus []uint
+}
+
+// DefaultRetryPolicy retries 503 (syncing or unavailable) and 429 (rate limited) responses, and transport errors.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+	Jitter:      0.5,
+	RetryStatus: []uint{http.StatusTooManyRequests, http.StatusServiceUnavailable},
+}
+
+var noRetry = RetryPolicy{MaxAttempts: 1}
+
+func (p *RetryPolicy) retries(req PreparedRequest) bool {
+	return p.MaxAttempts > 1 && isRetrySafe(req)
+}
+
+func (p *RetryPolicy) retryStatus(code int) bool {
+	for _, c := range p.RetryStatus {
+		if int(c) == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff decides if the given attempt should be retried, and how long to wait before retrying.
+// Either the response or the transport error is non-nil.
+func (p *RetryPolicy) backoff(ctx context.Context, attempt int, resp *http.Response, err error) (time.Duration, bool) {
+	if attempt >= p.MaxAttempts || ctx.Err() != nil {
+		return 0, false
+	}
+	if err == nil && !p.retryStatus(resp.StatusCode) {
+		return 0, false
+	}
+	backoff := p.MinBackoff << (attempt - 1)
+	if backoff > p.MaxBackoff || backoff <= 0 {
+		backoff = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		backoff -= time.Duration(rand.Float64() * p.Jitter * float64(backoff))
+	}
+	if resp != nil {
+		if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			// Don't retry sooner than the node asks for, and don't wait longer than MaxBackoff.
+			if after > p.MaxBackoff {
+				return 0, false
+			}
+			backoff = after
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(backoff).After(deadline) {
+		return 0, false
+	}
+	return backoff, true
+}
+
+// retryAfter parses a Retry-After header value, either a number of seconds or a HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
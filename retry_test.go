@@ -0,0 +1,151 @@
+package eth2api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyHTTPClient fails the first requests with a transport error.
+type flakyHTTPClient struct {
+	failures int32
+	cli      HTTPClient
+}
+
+func (c *flakyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if atomic.AddInt32(&c.failures, -1) >= 0 {
+		return nil, errors.New("connection refused")
+	}
+	return c.cli.Do(req)
+}
+
+func newRetryTestServer(t *testing.T, failures int32, code uint, headers Headers) (*httptest.Server, *int32) {
+	var attempts int32
+	router := NewHttpRouter()
+	handle := func(method ReqMethod) HandlerFn {
+		return func(ctx context.Context, req Request) PreparedResponse {
+			if atomic.AddInt32(&attempts, 1) <= failures {
+				return &BasicResponse{code: code, body: &ErrorMessage{CodeValue: code, Message: "try again"}, headers: headers}
+			}
+			var body string
+			if method == POST {
+				if err := req.DecodeBody(&body); err != nil {
+					return RespondBadInput(err)
+				}
+			}
+			return RespondOK(Wrap(body))
+		}
+	}
+	router.AddRoute(MakeRoute(GET, "/test", handle(GET)))
+	router.AddRoute(MakeRoute(POST, "/test", handle(POST)))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return srv, &attempts
+}
+
+func testRetryPolicy() *RetryPolicy {
+	p := DefaultRetryPolicy
+	p.MinBackoff = time.Millisecond
+	return &p
+}
+
+func TestRetryStatus(t *testing.T) {
+	srv, attempts := newRetryTestServer(t, 2, 503, nil)
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: testRetryPolicy()}
+	if code, err := cli.Request(context.Background(), PlainGET("/test")).Decode(nil); err != nil || code != 200 {
+		t.Fatalf("expected success after retries, got code %d, err: %v", code, err)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryMaxAttempts(t *testing.T) {
+	srv, attempts := newRetryTestServer(t, 5, 503, nil)
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: testRetryPolicy()}
+	if code, err := cli.Request(context.Background(), PlainGET("/test")).Decode(nil); err == nil || code != 503 {
+		t.Fatalf("expected 503 after max attempts, got code %d, err: %v", code, err)
+	}
+	if *attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryPOST(t *testing.T) {
+	srv, attempts := newRetryTestServer(t, 1, 503, nil)
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: testRetryPolicy()}
+	if code, _ := cli.Request(context.Background(), BodyPOST("/test", "hello")).Decode(nil); code != 503 {
+		t.Fatalf("expected POST not to be retried, got code %d", code)
+	}
+
+	atomic.StoreInt32(attempts, 0)
+	var out string
+	req := WithHeaders(WithRetrySafe(BodyPOST("/test", "hello")), Headers{"X-Test": "1"})
+	if code, err := cli.Request(context.Background(), req).Decode(Wrap(&out)); err != nil || code != 200 {
+		t.Fatalf("expected safe POST to be retried, got code %d, err: %v", code, err)
+	}
+	if out != "hello" {
+		t.Fatalf("body was not re-encoded for retry, got %q", out)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv, attempts := newRetryTestServer(t, 1, 429, Headers{"Retry-After": "0"})
+	policy := testRetryPolicy()
+	policy.MinBackoff = time.Hour
+	policy.MaxBackoff = time.Hour
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: policy}
+	if code, err := cli.Request(context.Background(), PlainGET("/test")).Decode(nil); err != nil || code != 200 {
+		t.Fatalf("expected retry after rate limit, got code %d, err: %v", code, err)
+	}
+	if *attempts != 2 {
+		t.Fatalf("expected 2 attempts, got %d", *attempts)
+	}
+}
+
+func TestRetryAfterMaxBackoff(t *testing.T) {
+	for _, after := range []string{"3600", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)} {
+		srv, attempts := newRetryTestServer(t, 1, 429, Headers{"Retry-After": after})
+		cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: testRetryPolicy()}
+		start := time.Now()
+		if code, _ := cli.Request(context.Background(), PlainGET("/test")).Decode(nil); code != 429 {
+			t.Fatalf("retry after %s: expected no retry past the max backoff, got code %d", after, code)
+		}
+		if *attempts != 1 || time.Since(start) > time.Second {
+			t.Fatalf("retry after %s: expected 1 attempt without waiting, got %d", after, *attempts)
+		}
+	}
+}
+
+func TestRetryDeadline(t *testing.T) {
+	srv, attempts := newRetryTestServer(t, 1, 503, Headers{"Retry-After": "3"})
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: JSONCodec{}, Retry: testRetryPolicy()}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if code, _ := cli.Request(ctx, PlainGET("/test")).Decode(nil); code != 503 {
+		t.Fatalf("expected no retry past the deadline, got code %d", code)
+	}
+	if *attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", *attempts)
+	}
+}
+
+func TestRetryTransportErr(t *testing.T) {
+	srv, _ := newRetryTestServer(t, 0, 200, nil)
+	cli := &Eth2HttpClient{Addr: srv.URL, Cli: &flakyHTTPClient{failures: 2, cli: srv.Client()}, Codec: JSONCodec{}, Retry: testRetryPolicy()}
+	if code, err := cli.Request(context.Background(), PlainGET("/test")).Decode(nil); err != nil || code != 200 {
+		t.Fatalf("expected success after transport errors, got code %d, err: %v", code, err)
+	}
+
+	cli.Cli = &flakyHTTPClient{failures: 1, cli: srv.Client()}
+	cli.Retry = nil
+	_, err := cli.Request(context.Background(), PlainGET("/test")).Decode(nil)
+	var te TransportErr
+	if !errors.As(err, &te) {
+		t.Fatalf("expected transport error without retry policy, got %v", err)
+	}
+}
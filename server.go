@@ -16,8 +16,8 @@ type Request interface {
 	DecodeBody(dst interface{}) error
 	Param(name string) string
 	Query(name string) (values []string, ok bool)
-
-	// TODO: maybe expose headers?
+	// Header returns the first value of the named request header. The name is case-insensitive.
+	Header(name string) (value string, ok bool)
 }
 
 type HandlerFn func(ctx context.Context, req Request) PreparedResponse
@@ -43,3 +43,43 @@ func (r *route) Handle(ctx context.Context, req Request) PreparedResponse {
 func MakeRoute(method ReqMethod, path string, handle HandlerFn) Route {
 	return &route{method, path, handle}
 }
+
+// Middleware wraps a route, e.g. for authentication, logging or rate limiting.
+// A middleware can handle the request itself, or call the Handle function of the wrapped route.
+// The method and route template of the returned route must not change.
+type Middleware func(route Route) Route
+
+// Chain composes the middleware into a single middleware. The first middleware is the outermost,
+// i.e. it sees the request first and the response last.
+func Chain(middleware ...Middleware) Middleware {
+	return func(route Route) Route {
+		for i := len(middleware) - 1; i >= 0; i-- {
+			route = middleware[i](route)
+		}
+		return route
+	}
+}
+
+// RouteGroup is a Server that wraps the routes with the middleware of the group,
+// before adding them to the parent server.
+// The middleware of the parent, e.g. of the HttpRouter or of a parent group, is the outer middleware.
+type RouteGroup struct {
+	Server     Server
+	Middleware []Middleware
+}
+
+var _ Server = (*RouteGroup)(nil)
+
+// Use appends middleware to the group. Middleware only applies to routes added after.
+func (g *RouteGroup) Use(middleware ...Middleware) {
+	g.Middleware = append(g.Middleware, middleware...)
+}
+
+// Group creates a sub-group, with additional middleware.
+func (g *RouteGroup) Group(middleware ...Middleware) *RouteGroup {
+	return &RouteGroup{Server: g, Middleware: middleware}
+}
+
+func (g *RouteGroup) AddRoute(route Route) {
+	g.Server.AddRoute(Chain(g.Middleware...)(route))
+}
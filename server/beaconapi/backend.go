@@ -20,10 +20,22 @@ type Publisher interface {
 	PublishSyncCommitteeMessage(ctx context.Context, msg *altair.SyncCommitteeMessage) error
 }
 
+// BlobPublisher is optionally implemented by the Publisher, to publish the blobs of blocks since deneb,
+// as published with the Eth-Consensus-Version header. Without it, the blobs are not published.
+type BlobPublisher interface {
+	PublishBlobs(ctx context.Context, block *common.BeaconBlockEnvelope, proofs eth2api.KZGProofs, blobs eth2api.Blobs) error
+}
+
 type BlockReader interface {
 	Get(slot common.Slot, root common.Root) (*common.BeaconBlockEnvelope, error)
 }
 
+// BlobReader reads the blob sidecars of blocks, since deneb.
+type BlobReader interface {
+	// Get returns the blob sidecars of the block, nil if the block has no blobs.
+	Get(slot common.Slot, root common.Root) (eth2api.BlobSidecars, error)
+}
+
 type AttestationPool interface {
 	Search(opts ...pool.AttSearchOption) (out []*phase0.Attestation)
 	AddAttestation(ctx context.Context, att *phase0.Attestation, committee common.CommitteeIndices) error
@@ -48,10 +60,35 @@ type SyncCommitteePool interface {
 	AddSyncCommitteeMessage(ctx context.Context, msg *altair.SyncCommitteeMessage) error
 }
 
+// EventSubscription receives the events of an EventBus subscription.
+type EventSubscription interface {
+	// Events channel, closed when the subscription ends,
+	// e.g. when the subscriber falls behind and is dropped by the bus.
+	Events() <-chan *eth2api.ServerEvent
+	// Close ends the subscription. Safe to call multiple times.
+	Close()
+}
+
+// EventBus fans out the events of the node to subscribers, filtered by topic.
+type EventBus interface {
+	Subscribe(topics []eth2api.EventTopic) EventSubscription
+}
+
+// EventReplayBus is an optional EventBus extension, to resume the event stream of a client
+// that reconnects with the ID of the last event it received.
+type EventReplayBus interface {
+	EventBus
+	// SubscribeAfter subscribes to the events after the event with the given ID,
+	// starting with the events that were already published after it.
+	// Returns false if events after the given ID are no longer available, or if the ID is unknown.
+	SubscribeAfter(topics []eth2api.EventTopic, lastEventID string) (sub EventSubscription, ok bool)
+}
+
 type BeaconBackend struct {
 	Spec      *common.Spec
 	Chain     beacon.Chain
 	BlockDB   BlockReader
+	BlobDB    BlobReader
 	Publisher Publisher
 
 	ProcessBlock func(ctx context.Context, block *common.BeaconBlockEnvelope) error
@@ -63,6 +100,8 @@ type BeaconBackend struct {
 	ProposerSlashingPool ProposerSlashingPool
 	VoluntaryExitPool    VoluntaryExitPool
 	SyncCommitteePool    SyncCommitteePool
+
+	Events EventBus
 }
 
 func (backend *BeaconBackend) BlockLookup(blockId eth2api.BlockId) (entry beacon.ChainEntry, ok bool) {
@@ -93,3 +132,44 @@ func (backend *BeaconBackend) BlockLookup(blockId eth2api.BlockId) (entry beacon
 		return nil, false
 	}
 }
+
+func (backend *BeaconBackend) StateLookup(stateId eth2api.StateId) (entry beacon.ChainEntry, ok bool) {
+	switch id := stateId.(type) {
+	case eth2api.StateIdRoot:
+		return backend.Chain.ByStateRoot(common.Root(id))
+	case eth2api.StateIdSlot:
+		// prefer the post-block state, if the slot has a block.
+		entry, ok = backend.Chain.ByCanonStep(common.AsStep(common.Slot(id), true))
+		if !ok {
+			entry, ok = backend.Chain.ByCanonStep(common.AsStep(common.Slot(id), false))
+		}
+		return
+	case eth2api.StateIdStrMode:
+		switch id {
+		case eth2api.StateHead:
+			entry, err := backend.Chain.Head()
+			return entry, err == nil
+		case eth2api.StateFinalized:
+			entry, err := backend.Chain.Finalized()
+			return entry, err == nil
+		case eth2api.StateJustified:
+			entry, err := backend.Chain.Justified()
+			return entry, err == nil
+		case eth2api.StateGenesis:
+			return backend.Chain.ByCanonStep(common.AsStep(common.Slot(0), true))
+		default:
+			return nil, false
+		}
+	default:
+		return nil, false
+	}
+}
+
+// ForkDigest computes the fork digest for the given fork name, as used in the Eth-Consensus-Version header.
+func (backend *BeaconBackend) ForkDigest(version string) (common.ForkDigest, error) {
+	f, err := eth2api.ForkByName(version)
+	if err != nil {
+		return common.ForkDigest{}, err
+	}
+	return common.ComputeForkDigest(f.Version(backend.Spec), backend.Chain.Genesis().ValidatorsRoot), nil
+}
@@ -0,0 +1,163 @@
+package beaconapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	blsu "github.com/protolambda/bls12-381-util"
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/tree"
+)
+
+// testChain only implements the parts of the chain that the tested routes use.
+// The first entry is the genesis, finalized and justified entry, the last entry is the head.
+type testChain struct {
+	beacon.Chain
+	genesis beacon.GenesisInfo
+	entries []*testEntry
+}
+
+func (c *testChain) Genesis() beacon.GenesisInfo {
+	return c.genesis
+}
+
+func (c *testChain) ByStateRoot(root common.Root) (beacon.ChainEntry, bool) {
+	for _, e := range c.entries {
+		if r, _ := e.StateRoot(); r == root {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func (c *testChain) ByCanonStep(step common.Step) (beacon.ChainEntry, bool) {
+	for _, e := range c.entries {
+		if e.Step() == step {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func (c *testChain) Head() (beacon.ChainEntry, error) {
+	return c.entries[len(c.entries)-1], nil
+}
+
+func (c *testChain) Finalized() (beacon.ChainEntry, error) {
+	return c.entries[0], nil
+}
+
+func (c *testChain) Justified() (beacon.ChainEntry, error) {
+	return c.entries[0], nil
+}
+
+type testEntry struct {
+	slot  common.Slot
+	state common.BeaconState
+	epc   *common.EpochsContext
+}
+
+func (e *testEntry) Step() common.Step {
+	return common.AsStep(e.slot, true)
+}
+
+func (e *testEntry) BlockRoot() (common.Root, error) {
+	return common.Root{}, nil
+}
+
+func (e *testEntry) ParentRoot() (common.Root, error) {
+	return common.Root{}, nil
+}
+
+func (e *testEntry) StateRoot() (common.Root, error) {
+	return e.state.HashTreeRoot(tree.GetHashFn()), nil
+}
+
+func (e *testEntry) EpochsContext(ctx context.Context) (*common.EpochsContext, error) {
+	return e.epc, nil
+}
+
+func (e *testEntry) State(ctx context.Context) (common.BeaconState, error) {
+	return e.state, nil
+}
+
+// newTestBackend creates a backend of a minimal chain that forks to altair at epoch 1.
+func newTestBackend() (*BeaconBackend, *testPublisher) {
+	spec := *configs.Minimal
+	spec.ALTAIR_FORK_EPOCH = 1
+	chain := &testChain{genesis: beacon.GenesisInfo{ValidatorsRoot: common.Root{0x42}}}
+	publisher := &testPublisher{}
+	return &BeaconBackend{
+		Spec:         &spec,
+		Chain:        chain,
+		Publisher:    publisher,
+		ProcessBlock: func(ctx context.Context, block *common.BeaconBlockEnvelope) error { return nil },
+		ForkDecoder:  beacon.NewForkDecoder(&spec, chain.genesis.ValidatorsRoot),
+	}, publisher
+}
+
+const testValidatorCount = 64
+
+// addTestStates adds a phase0 genesis state at slot 0, and an altair head state at slot 8, to the chain of the backend.
+// Both states have testValidatorCount active validators. The pubkeys of the validators are returned.
+func addTestStates(t *testing.T, backend *BeaconBackend) []common.BLSPubkey {
+	spec := backend.Spec
+	pubkeys := make([]common.BLSPubkey, testValidatorCount)
+	validators := make([]phase0.KickstartValidatorData, testValidatorCount)
+	for i := range validators {
+		var sk blsu.SecretKey
+		if err := sk.Deserialize(&[32]byte{31: byte(i + 1)}); err != nil {
+			t.Fatal(err)
+		}
+		pk, err := blsu.SkToPk(&sk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pubkeys[i] = pk.Serialize()
+		validators[i] = phase0.KickstartValidatorData{Pubkey: pubkeys[i], Balance: spec.MAX_EFFECTIVE_BALANCE}
+	}
+	genesis, genesisEpc, err := phase0.KickStartState(spec, common.Root{}, 0, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pre, _, err := phase0.KickStartState(spec, common.Root{}, 0, validators)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pre.SetSlot(8); err != nil {
+		t.Fatal(err)
+	}
+	preEpc, err := common.NewEpochsContext(spec, pre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := altair.UpgradeToAltair(spec, preEpc, pre)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headEpc, err := common.NewEpochsContext(spec, head)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := backend.Chain.(*testChain)
+	chain.entries = append(chain.entries,
+		&testEntry{slot: 0, state: genesis, epc: genesisEpc},
+		&testEntry{slot: 8, state: head, epc: headEpc})
+	return pubkeys
+}
+
+func serve(t *testing.T, routes ...eth2api.Route) *eth2api.Eth2HttpClient {
+	router := eth2api.NewHttpRouter()
+	for _, route := range routes {
+		router.AddRoute(route)
+	}
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+}
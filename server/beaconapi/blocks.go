@@ -4,12 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 
 	"github.com/protolambda/eth2api"
 	"github.com/protolambda/zrnt/eth2/beacon"
 	"github.com/protolambda/zrnt/eth2/beacon/altair"
-	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
-	"github.com/protolambda/zrnt/eth2/beacon/capella"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/phase0"
 )
@@ -109,20 +108,15 @@ func Blockv2(backend *BeaconBackend) eth2api.Route {
 			if err != nil {
 				return eth2api.RespondInternalError(fmt.Errorf("failed to construct typed beacon block: %v", err))
 			}
-			var version string
-			switch data.(type) {
-			case *phase0.SignedBeaconBlock:
-				version = "phase0"
-			case *altair.SignedBeaconBlock:
-				version = "altair"
-			case *bellatrix.SignedBeaconBlock:
-				version = "bellatrix"
-			case *capella.SignedBeaconBlock:
-				version = "capella"
-			default:
-				return eth2api.RespondInternalError(fmt.Errorf("unknown block type %T", data))
+			signed, ok := data.(eth2api.SignedBeaconBlock)
+			if !ok {
+				return eth2api.RespondInternalError(fmt.Errorf("unrecognized signed beacon block type: %T", data))
+			}
+			version, err := eth2api.SignedBeaconBlockVersion(signed)
+			if err != nil {
+				return eth2api.RespondInternalError(err)
 			}
-			return eth2api.RespondOK(&eth2api.VersionedBeaconBlock{Version: version, Data: data})
+			return eth2api.RespondOKVersioned(&eth2api.VersionedSignedBeaconBlock{Version: version, Data: signed})
 		})
 }
 
@@ -165,14 +159,47 @@ func (h *slotHack) UnmarshalJSON(b []byte) error {
 // The beacon node is not required to validate the signed BeaconBlock, and a successful response (20X) only indicates that the broadcast has been successful.
 // The beacon node is expected to integrate the new block into its state, and therefore validate the block internally,
 // however blocks which fail the validation are still broadcast but a different status code is returned (202)
+//
+// With the Eth-Consensus-Version header, the body is decoded as the signed block contents of the fork,
+// which since deneb includes the blobs of the block, see BlobPublisher.
 func PublishBlock(backend *BeaconBackend) eth2api.Route {
 	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/blocks",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
-			var block slotHack
-			if err := req.DecodeBody(&block); err != nil {
-				return eth2api.RespondBadInput(err)
+			var blockEnvelop *common.BeaconBlockEnvelope
+			var contents *eth2api.SignedBlockContents
+			if version, ok := req.Header(eth2api.ConsensusVersionHeader); ok {
+				fork, err := eth2api.ForkByName(version)
+				if err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				forkDigest, err := backend.ForkDigest(version)
+				if err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				// Since deneb the block is published with its blobs.
+				block := fork.SignedBlockContents()
+				if err := req.DecodeBody(block); err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				if c, ok := block.(*eth2api.SignedBlockContents); ok {
+					contents = c
+					blockEnvelop = c.SignedBlock.Envelope(backend.Spec, forkDigest)
+				} else {
+					blockEnvelop = block.(eth2api.SignedBeaconBlock).Envelope(backend.Spec, forkDigest)
+				}
+			} else {
+				// No version header, fall back to determining the fork by the slot of the block.
+				block := slotHack{backend: backend}
+				if err := req.DecodeBody(&block); err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				blockEnvelop = block.dest
+			}
+			if p, ok := backend.Publisher.(BlobPublisher); ok && contents != nil {
+				if err := p.PublishBlobs(ctx, blockEnvelop, contents.KZGProofs, contents.Blobs); err != nil {
+					return eth2api.RespondInternalError(fmt.Errorf("failed to publish blobs: %v", err))
+				}
 			}
-			blockEnvelop := block.dest
 			syncing, err := backend.Publisher.PublishBlock(ctx, blockEnvelop)
 			if err != nil {
 				return eth2api.RespondInternalError(fmt.Errorf("failed to publish block: %v", err))
@@ -189,7 +216,7 @@ func PublishBlock(backend *BeaconBackend) eth2api.Route {
 
 // Serves hashTreeRoot of BeaconBlock/BeaconBlockHeader.
 func BlockRoot(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/blocks/:blockId",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/blocks/:blockId/root",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			blockId, err := eth2api.ParseBlockId(req.Param("blockId"))
 			if err != nil {
@@ -207,3 +234,48 @@ func BlockRoot(backend *BeaconBackend) eth2api.Route {
 			return eth2api.RespondOK(eth2api.Wrap(&out))
 		})
 }
+
+// Serves the blob sidecars of the given block id, optionally filtered by the indices query param.
+// Without a BlobDB the route is not implemented (501).
+func BlobSidecars(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/blob_sidecars/:blockId",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			if backend.BlobDB == nil {
+				return eth2api.RespondNotImplemented("blob sidecars are not available")
+			}
+			blockId, err := eth2api.ParseBlockId(req.Param("blockId"))
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var indices map[uint64]struct{}
+			if vals := queryList(req, "indices"); vals != nil {
+				indices = make(map[uint64]struct{}, len(vals))
+				for _, v := range vals {
+					i, err := strconv.ParseUint(v, 10, 64)
+					if err != nil {
+						return eth2api.RespondBadInput(fmt.Errorf("bad blob index %q: %v", v, err))
+					}
+					indices[i] = struct{}{}
+				}
+			}
+			entry, ok := backend.BlockLookup(blockId)
+			if !ok {
+				return eth2api.RespondNotFound("Block not found")
+			}
+			blockRoot, err := entry.BlockRoot()
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load block root: %v", err))
+			}
+			sidecars, err := backend.BlobDB.Get(entry.Step().Slot(), blockRoot)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load blob sidecars: %v", err))
+			}
+			out := make(eth2api.BlobSidecars, 0, len(sidecars))
+			for _, sidecar := range sidecars {
+				if _, ok := indices[uint64(sidecar.Index)]; ok || indices == nil {
+					out = append(out, sidecar)
+				}
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&out))
+		})
+}
@@ -0,0 +1,161 @@
+package beaconapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/ztyp/view"
+)
+
+type testPublisher struct {
+	Publisher
+	blocks []*common.BeaconBlockEnvelope
+	blobs  []eth2api.Blobs
+}
+
+func (p *testPublisher) PublishBlobs(ctx context.Context, block *common.BeaconBlockEnvelope, proofs eth2api.KZGProofs, blobs eth2api.Blobs) error {
+	p.blobs = append(p.blobs, blobs)
+	return nil
+}
+
+func (p *testPublisher) PublishBlock(ctx context.Context, block *common.BeaconBlockEnvelope) (syncing bool, err error) {
+	p.blocks = append(p.blocks, block)
+	return false, nil
+}
+
+func TestPublishBlock(t *testing.T) {
+	backend, publisher := newTestBackend()
+	cli := serve(t, PublishBlock(backend))
+	ctx := context.Background()
+
+	phase0Block := &phase0.SignedBeaconBlock{Message: phase0.BeaconBlock{Slot: 3}}
+	altairBlock := &altair.SignedBeaconBlock{Message: altair.BeaconBlock{Slot: 9}}
+	altairBlock.Message.Body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, backend.Spec.SYNC_COMMITTEE_SIZE/8)
+
+	// without version header, the fork is determined by the slot of the block
+	for _, block := range []eth2api.SignedBeaconBlock{phase0Block, altairBlock} {
+		if valid, err := beaconapi.PublishBlock(ctx, cli, block); err != nil || !valid {
+			t.Fatalf("unexpected result: valid %v, err %v", valid, err)
+		}
+	}
+	// with version header, the fork is determined by the header
+	req := eth2api.WithHeaders(eth2api.BodyPOST("/eth/v1/beacon/blocks", altairBlock),
+		eth2api.Headers{eth2api.ConsensusVersionHeader: "altair"})
+	if _, err := cli.Request(ctx, req).Decode(nil); err != nil {
+		t.Fatal(err)
+	}
+	req = eth2api.WithHeaders(eth2api.BodyPOST("/eth/v1/beacon/blocks", altairBlock),
+		eth2api.Headers{eth2api.ConsensusVersionHeader: "sharding"})
+	if code, err := cli.Request(ctx, req).Decode(nil); code != 400 {
+		t.Fatalf("expected bad input for unknown version, got %d: %v", code, err)
+	}
+
+	// since deneb the block is published with its blobs
+	denebBlock := &deneb.SignedBeaconBlock{Message: deneb.BeaconBlock{Slot: 20}}
+	denebBlock.Message.Body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, backend.Spec.SYNC_COMMITTEE_SIZE/8)
+	denebBlock.Message.Body.BlobKZGCommitments = deneb.KZGCommitments{{0xc0}}
+	blob := make(eth2api.Blob, eth2api.BlobSize(backend.Spec))
+	blob[0] = 0xb1
+	contents := &eth2api.VersionedSignedBlockContents{Version: "deneb", Data: &eth2api.SignedBlockContents{
+		SignedBlock: denebBlock,
+		KZGProofs:   eth2api.KZGProofs{{0xaa}},
+		Blobs:       eth2api.Blobs{blob},
+	}}
+	if valid, err := beaconapi.PublishBlockContents(ctx, cli, contents); err != nil || !valid {
+		t.Fatalf("unexpected result: valid %v, err %v", valid, err)
+	}
+	if len(publisher.blobs) != 1 || len(publisher.blobs[0]) != 1 || publisher.blobs[0][0][0] != 0xb1 {
+		t.Fatalf("unexpected published blobs: %v", publisher.blobs)
+	}
+
+	phase0Digest, _ := backend.ForkDigest("phase0")
+	altairDigest, _ := backend.ForkDigest("altair")
+	denebDigest, _ := backend.ForkDigest("deneb")
+	if len(publisher.blocks) != 4 {
+		t.Fatalf("unexpected published blocks: %d", len(publisher.blocks))
+	}
+	for i, expected := range []struct {
+		slot   common.Slot
+		digest common.ForkDigest
+	}{{3, phase0Digest}, {9, altairDigest}, {9, altairDigest}, {20, denebDigest}} {
+		block := publisher.blocks[i]
+		if block.Slot != expected.slot || block.ForkDigest != expected.digest {
+			t.Errorf("block %d: unexpected slot %d and fork digest %s", i, block.Slot, block.ForkDigest)
+		}
+	}
+}
+
+type testBlobDB struct {
+	sidecars eth2api.BlobSidecars
+}
+
+func (db *testBlobDB) Get(slot common.Slot, root common.Root) (eth2api.BlobSidecars, error) {
+	return db.sidecars, nil
+}
+
+func TestBlobSidecars(t *testing.T) {
+	backend, _ := newTestBackend()
+	backend.Chain.(*testChain).entries = []*testEntry{{slot: 5}}
+	route := BlobSidecars(backend)
+	ctx := context.Background()
+
+	// without a blob DB the route is not implemented
+	cli := serve(t, route)
+	if code, err := cli.Request(ctx, eth2api.PlainGET("/eth/v1/beacon/blob_sidecars/head")).Decode(nil); code != 501 {
+		t.Fatalf("expected not implemented, got %d: %v", code, err)
+	}
+
+	db := &testBlobDB{}
+	for i := 0; i < 3; i++ {
+		sidecar := eth2api.BlobSidecar{
+			Index:                       view.Uint64View(i),
+			Blob:                        make(eth2api.Blob, eth2api.BlobSize(backend.Spec)),
+			KZGCommitment:               common.KZGCommitment{byte(i)},
+			KZGCommitmentInclusionProof: make(eth2api.KZGCommitmentInclusionProof, backend.Spec.KZG_COMMITMENT_INCLUSION_PROOF_DEPTH),
+		}
+		sidecar.Blob[0] = byte(i)
+		db.sidecars = append(db.sidecars, sidecar)
+	}
+	backend.BlobDB = db
+
+	router := eth2api.NewHttpRouter()
+	router.Codecs = eth2api.Codecs{eth2api.JSONCodec{}, eth2api.SSZCodec{Spec: backend.Spec}}
+	router.AddRoute(route)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cli = &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	req := eth2api.QueryGET(eth2api.Query{"indices": "0,x"}, "/eth/v1/beacon/blob_sidecars/head")
+	if code, err := cli.Request(ctx, req).Decode(nil); code != 400 {
+		t.Fatalf("expected bad input for bad index, got %d: %v", code, err)
+	}
+	for _, codec := range []eth2api.Codec{eth2api.JSONCodec{}, eth2api.SSZCodec{Spec: backend.Spec}} {
+		cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: codec}
+		var all eth2api.BlobSidecars
+		if exists, err := beaconapi.BlobSidecars(ctx, cli, eth2api.BlockHead, nil, &all); err != nil || !exists {
+			t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+		}
+		if len(all) != 3 || all[2].Blob[0] != 2 || all[2].KZGCommitment[0] != 2 {
+			t.Fatalf("unexpected blob sidecars: %d", len(all))
+		}
+
+		var filtered eth2api.BlobSidecars
+		if exists, err := beaconapi.BlobSidecars(ctx, cli, eth2api.BlockHead, []view.Uint64View{0, 2}, &filtered); err != nil || !exists {
+			t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+		}
+		if len(filtered) != 2 || filtered[0].Index != 0 || filtered[1].Index != 2 || filtered[1].Blob[0] != 2 {
+			t.Fatalf("unexpected filtered blob sidecars: %d", len(filtered))
+		}
+
+		if exists, err := beaconapi.BlobSidecars(ctx, cli, eth2api.BlockIdSlot(6), nil, new(eth2api.BlobSidecars)); err != nil || exists {
+			t.Fatalf("expected unknown block, got exists %v, err %v", exists, err)
+		}
+	}
+}
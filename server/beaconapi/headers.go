@@ -11,7 +11,7 @@ import (
 
 // Serve block header for given block id.
 func BlockHeader(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/headers/:blockId",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/headers/:blockId",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			blockId, err := eth2api.ParseBlockId(req.Param("blockId"))
 			if err != nil {
@@ -54,7 +54,7 @@ func BlockHeader(backend *BeaconBackend) eth2api.Route {
 
 // Serves block headers matching given query. By default it will serve current head slot blocks.
 func BlockHeaders(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/headers",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/headers",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var parentRootFilter *common.Root
 			parentRootVals, ok := req.Query("parent_root")
@@ -14,7 +14,7 @@ import (
 
 // Serves attestations known by the node but not necessarily incorporated into any block
 func PoolAttestations(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/pool/attestations",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/pool/attestations",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var opts []pool.AttSearchOption
 
@@ -42,7 +42,7 @@ func PoolAttestations(backend *BeaconBackend) eth2api.Route {
 
 // Handles publishing of attestations, stores them in the pool and sends them to the publisher.
 func PublishAttestations(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.POST, "eth/v1/beacon/pool/attestations",
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/attestations",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var atts []phase0.Attestation
 			if err := req.DecodeBody(&atts); err != nil {
@@ -90,7 +90,7 @@ func PublishAttestations(backend *BeaconBackend) eth2api.Route {
 
 // Retrieves attester slashings known by the node but not necessarily incorporated into any block
 func PoolAttesterSlashings(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/pool/attester_slashings",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/pool/attester_slashings",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			return eth2api.RespondOK(eth2api.Wrap(backend.AttesterSlashingPool.All()))
 		})
@@ -98,7 +98,7 @@ func PoolAttesterSlashings(backend *BeaconBackend) eth2api.Route {
 
 // Submits AttesterSlashing object to node's pool and if passes validation node MUST broadcast it to network.
 func PublishAttesterSlashing(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.POST, "eth/v1/beacon/pool/attester_slashings",
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/attester_slashings",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var attSlashing phase0.AttesterSlashing
 			if err := req.DecodeBody(&attSlashing); err != nil {
@@ -116,7 +116,7 @@ func PublishAttesterSlashing(backend *BeaconBackend) eth2api.Route {
 
 // Retrieves proposer slashings known by the node but not necessarily incorporated into any block
 func PoolProposerSlashings(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/pool/proposer_slashings",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/pool/proposer_slashings",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			return eth2api.RespondOK(eth2api.Wrap(backend.ProposerSlashingPool.All()))
 		})
@@ -124,7 +124,7 @@ func PoolProposerSlashings(backend *BeaconBackend) eth2api.Route {
 
 // Submits ProposerSlashing object to node's pool and if passes validation node MUST broadcast it to network.
 func PublishProposerSlashing(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.POST, "eth/v1/beacon/pool/proposer_slashings",
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/proposer_slashings",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var propSlashing phase0.ProposerSlashing
 			if err := req.DecodeBody(&propSlashing); err != nil {
@@ -145,7 +145,7 @@ func PublishProposerSlashing(backend *BeaconBackend) eth2api.Route {
 // If a sync committee signature is validated successfully the node MUST publish that sync committee signature on all applicable subnets.
 // If one or more sync committee signatures fail validation the node MUST return a 400 error with details of which sync committee signatures have failed, and why.
 func PublishSyncCommittees(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.POST, "eth/v1/beacon/pool/sync_committees",
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/sync_committees",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var atts []altair.SyncCommitteeMessage
 			if err := req.DecodeBody(&atts); err != nil {
@@ -175,7 +175,7 @@ func PublishSyncCommittees(backend *BeaconBackend) eth2api.Route {
 
 // Retrieves voluntary exits known by the node but not necessarily incorporated into any block
 func PoolVoluntaryExits(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.GET, "eth/v1/beacon/pool/voluntary_exits",
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/pool/voluntary_exits",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			return eth2api.RespondOK(eth2api.Wrap(backend.VoluntaryExitPool.All()))
 		})
@@ -183,7 +183,7 @@ func PoolVoluntaryExits(backend *BeaconBackend) eth2api.Route {
 
 // Submits SignedVoluntaryExit object to node's pool and if passes validation node MUST broadcast it to network.
 func PublishVoluntaryExit(backend *BeaconBackend) eth2api.Route {
-	return eth2api.MakeRoute(eth2api.POST, "eth/v1/beacon/pool/voluntary_exits",
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/pool/voluntary_exits",
 		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
 			var signedExit phase0.SignedVoluntaryExit
 			if err := req.DecodeBody(&signedExit); err != nil {
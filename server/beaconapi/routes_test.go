@@ -0,0 +1,44 @@
+package beaconapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+)
+
+// every route of the package, to check that they can all be served by the same router
+var allRoutes = []func(backend *BeaconBackend) eth2api.Route{
+	Genesis,
+	StateRoot, Fork, FinalityCheckpoints, EpochCommittees, SyncCommittees,
+	StateValidator, StateValidators, StateValidatorBalances,
+	BlockHeader, BlockHeaders,
+	Block, Blockv2, BlockRoot, BlockAttestations, PublishBlock, BlobSidecars,
+	PoolAttestations, PublishAttestations,
+	PoolAttesterSlashings, PublishAttesterSlashing,
+	PoolProposerSlashings, PublishProposerSlashing,
+	PublishSyncCommittees,
+	PoolVoluntaryExits, PublishVoluntaryExit,
+}
+
+func TestRoutes(t *testing.T) {
+	backend := &BeaconBackend{}
+	router := eth2api.NewHttpRouter()
+	for _, fn := range allRoutes {
+		route := fn(backend)
+		if err := addRoute(router, route); err != nil {
+			t.Errorf("failed to add route %s %s: %v", route.Method(), route.Route(), err)
+		}
+	}
+}
+
+// addRoute adds the route to the router, the underlying router panics on invalid or conflicting routes.
+func addRoute(router *eth2api.HttpRouter, route eth2api.Route) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("%v", v)
+		}
+	}()
+	router.AddRoute(route)
+	return nil
+}
@@ -0,0 +1,471 @@
+package beaconapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+// stateEntry looks up the chain entry of the stateId path param, or returns an error response.
+func stateEntry(backend *BeaconBackend, req eth2api.Request) (beacon.ChainEntry, eth2api.PreparedResponse) {
+	stateId, err := eth2api.ParseStateId(req.Param("stateId"))
+	if err != nil {
+		return nil, eth2api.RespondBadInput(err)
+	}
+	entry, ok := backend.StateLookup(stateId)
+	if !ok {
+		return nil, eth2api.RespondNotFound("State not found")
+	}
+	return entry, nil
+}
+
+// queryUint parses the first value of the given query param, if present.
+func queryUint(req eth2api.Request, name string) (v uint64, ok bool, err error) {
+	vals, ok := req.Query(name)
+	if !ok || len(vals) == 0 {
+		return 0, false, nil
+	}
+	v, err = strconv.ParseUint(vals[0], 0, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("bad %s query param: %v", name, err)
+	}
+	return v, true, nil
+}
+
+// queryList splits all values of the given query param by comma, returns nil if not present.
+func queryList(req eth2api.Request, name string) (out []string) {
+	vals, _ := req.Query(name)
+	for _, v := range vals {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
+
+// Serves HashTreeRoot for state with given 'stateId'.
+func StateRoot(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/root",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			stateRoot, err := entry.StateRoot()
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load state root: %v", err))
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&eth2api.RootResponse{Root: stateRoot}))
+		})
+}
+
+// Serves Fork object for state with given 'stateId'.
+func Fork(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/fork",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			state, err := entry.State(ctx)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load state: %v", err))
+			}
+			fork, err := state.Fork()
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load fork: %v", err))
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&fork))
+		})
+}
+
+// Serves finality checkpoints for state with given 'stateId'.
+// In case finality is not yet achieved, checkpoint should return epoch 0 and ZERO_HASH as root.
+func FinalityCheckpoints(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/finality_checkpoints",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			state, err := entry.State(ctx)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load state: %v", err))
+			}
+			var out eth2api.FinalityCheckpoints
+			if out.PreviousJustified, err = state.PreviousJustifiedCheckpoint(); err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load previous justified checkpoint: %v", err))
+			}
+			if out.CurrentJustified, err = state.CurrentJustifiedCheckpoint(); err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load current justified checkpoint: %v", err))
+			}
+			if out.Finalized, err = state.FinalizedCheckpoint(); err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load finalized checkpoint: %v", err))
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&out))
+		})
+}
+
+// Serves the committees for the given state.
+//
+// Optional query parameters:
+// - epoch: Fetch committees for the given epoch.  If not present then the committees for the epoch of the state will be obtained.
+// - index: Restrict returned values to those matching the supplied committee index.
+// - slot: Restrict returned values to those matching the supplied slot.
+func EpochCommittees(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/committees",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			epoch := backend.Spec.SlotToEpoch(entry.Step().Slot())
+			if v, ok, err := queryUint(req, "epoch"); err != nil {
+				return eth2api.RespondBadInput(err)
+			} else if ok {
+				epoch = common.Epoch(v)
+			}
+			index, hasIndex, err := queryUint(req, "index")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			slot, hasSlot, err := queryUint(req, "slot")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if hasSlot && backend.Spec.SlotToEpoch(common.Slot(slot)) != epoch {
+				return eth2api.RespondBadInput(fmt.Errorf("slot %d is not in epoch %d", slot, epoch))
+			}
+			epc, err := entry.EpochsContext(ctx)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load epochs context: %v", err))
+			}
+			if epoch+1 < epc.CurrentEpoch.Epoch || epoch > epc.CurrentEpoch.Epoch+1 {
+				return eth2api.RespondBadInput(fmt.Errorf("epoch %d is not within range of the state epoch %d", epoch, epc.CurrentEpoch.Epoch))
+			}
+			commsPerSlot, err := epc.GetCommitteeCountPerSlot(epoch)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to get committee count: %v", err))
+			}
+			startSlot, err := backend.Spec.EpochStartSlot(epoch)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var out []eth2api.Committee
+			for s := startSlot; s < startSlot+backend.Spec.SLOTS_PER_EPOCH; s++ {
+				if hasSlot && s != common.Slot(slot) {
+					continue
+				}
+				for i := common.CommitteeIndex(0); i < common.CommitteeIndex(commsPerSlot); i++ {
+					if hasIndex && i != common.CommitteeIndex(index) {
+						continue
+					}
+					comm, err := epc.GetBeaconCommittee(s, i)
+					if err != nil {
+						return eth2api.RespondInternalError(fmt.Errorf("failed to get committee %d at slot %d: %v", i, s, err))
+					}
+					out = append(out, eth2api.Committee{Index: i, Slot: s, Validators: comm})
+				}
+			}
+			return eth2api.RespondOK(eth2api.Wrap(out))
+		})
+}
+
+// Serves the sync committees for the given state. Optionally by epoch (defaults to epoch current to the state).
+func SyncCommittees(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/sync_committees",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			stateEpoch := backend.Spec.SlotToEpoch(entry.Step().Slot())
+			epoch := stateEpoch
+			if v, ok, err := queryUint(req, "epoch"); err != nil {
+				return eth2api.RespondBadInput(err)
+			} else if ok {
+				epoch = common.Epoch(v)
+			}
+			epc, err := entry.EpochsContext(ctx)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load epochs context: %v", err))
+			}
+			if epc.CurrentSyncCommittee == nil {
+				return eth2api.RespondBadInput(fmt.Errorf("state at epoch %d has no sync committees", stateEpoch))
+			}
+			period := epoch / backend.Spec.EPOCHS_PER_SYNC_COMMITTEE_PERIOD
+			statePeriod := stateEpoch / backend.Spec.EPOCHS_PER_SYNC_COMMITTEE_PERIOD
+			var committee *common.IndexedSyncCommittee
+			switch period {
+			case statePeriod:
+				committee = epc.CurrentSyncCommittee
+			case statePeriod + 1:
+				committee = epc.NextSyncCommittee
+			default:
+				return eth2api.RespondBadInput(fmt.Errorf("epoch %d is not within range of the sync committee period of the state", epoch))
+			}
+			out := eth2api.SyncCommittees{
+				Validators:          committee.Indices,
+				ValidatorAggregates: make([][]common.ValidatorIndex, 0, common.SYNC_COMMITTEE_SUBNET_COUNT),
+			}
+			for i := uint64(0); i < common.SYNC_COMMITTEE_SUBNET_COUNT; i++ {
+				_, indices, err := committee.Subcommittee(backend.Spec, i)
+				if err != nil {
+					return eth2api.RespondInternalError(fmt.Errorf("failed to get sync subcommittee %d: %v", i, err))
+				}
+				out.ValidatorAggregates = append(out.ValidatorAggregates, indices)
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&out))
+		})
+}
+
+// Serves validator specified by state and id or public key along with status and balance.
+func StateValidator(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/validators/:validatorId",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			validatorId, err := eth2api.ParseValidatorId(req.Param("validatorId"))
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			vals, err := loadValidators(ctx, backend, entry, []eth2api.ValidatorId{validatorId})
+			if err != nil {
+				return eth2api.RespondInternalError(err)
+			}
+			if len(vals) == 0 {
+				return eth2api.RespondNotFound("Validator not found")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&vals[0]))
+		})
+}
+
+// Serves filterable list of validators with their balance, status and index.
+//
+// Optional query parameters:
+// - id: validator indices or pubkeys, validators that are not found are omitted.
+// - status: validator statuses, or generic statuses like "active".
+func StateValidators(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/validators",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			ids, err := parseValidatorIds(req)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var statusFilter []eth2api.ValidatorStatus
+			for _, v := range queryList(req, "status") {
+				statusFilter = append(statusFilter, eth2api.ValidatorStatus(v))
+			}
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			vals, err := loadValidators(ctx, backend, entry, ids)
+			if err != nil {
+				return eth2api.RespondInternalError(err)
+			}
+			if statusFilter != nil {
+				filtered := make([]eth2api.ValidatorResponse, 0, len(vals))
+				for _, v := range vals {
+					for _, f := range statusFilter {
+						if v.Status.Matches(f) {
+							filtered = append(filtered, v)
+							break
+						}
+					}
+				}
+				vals = filtered
+			}
+			return eth2api.RespondOK(eth2api.Wrap(vals))
+		})
+}
+
+// Serves filterable list of validator balances.
+//
+// Optional query parameters:
+// - id: validator indices or pubkeys, validators that are not found are omitted.
+func StateValidatorBalances(backend *BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/validator_balances",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			ids, err := parseValidatorIds(req)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			entry, errResp := stateEntry(backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			state, err := entry.State(ctx)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load state: %v", err))
+			}
+			indices, err := resolveValidatorIndices(ctx, entry, state, ids)
+			if err != nil {
+				return eth2api.RespondInternalError(err)
+			}
+			balances, err := state.Balances()
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to load balances: %v", err))
+			}
+			out := make([]eth2api.ValidatorBalanceResponse, 0, len(indices))
+			for _, i := range indices {
+				bal, err := balances.GetBalance(i)
+				if err != nil {
+					return eth2api.RespondInternalError(fmt.Errorf("failed to load balance of validator %d: %v", i, err))
+				}
+				out = append(out, eth2api.ValidatorBalanceResponse{Index: i, Balance: bal})
+			}
+			return eth2api.RespondOK(eth2api.Wrap(out))
+		})
+}
+
+// parseValidatorIds parses the "id" query param, returns nil if not present.
+func parseValidatorIds(req eth2api.Request) ([]eth2api.ValidatorId, error) {
+	var ids []eth2api.ValidatorId
+	for _, v := range queryList(req, "id") {
+		id, err := eth2api.ParseValidatorId(v)
+		if err != nil {
+			return nil, fmt.Errorf("bad validator id %q: %v", v, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// resolveValidatorIndices resolves the validator ids to indices, omitting unknown validators.
+// All validator indices are returned if ids is nil.
+func resolveValidatorIndices(ctx context.Context, entry beacon.ChainEntry, state common.BeaconState, ids []eth2api.ValidatorId) ([]common.ValidatorIndex, error) {
+	vals, err := state.Validators()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validators: %v", err)
+	}
+	count, err := vals.ValidatorCount()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validator count: %v", err)
+	}
+	if ids == nil {
+		out := make([]common.ValidatorIndex, count)
+		for i := range out {
+			out[i] = common.ValidatorIndex(i)
+		}
+		return out, nil
+	}
+	var epc *common.EpochsContext
+	out := make([]common.ValidatorIndex, 0, len(ids))
+	for _, id := range ids {
+		switch x := id.(type) {
+		case eth2api.ValidatorIdIndex:
+			if uint64(x) < count {
+				out = append(out, common.ValidatorIndex(x))
+			}
+		case eth2api.ValidatorIdPubkey:
+			if epc == nil {
+				epc, err = entry.EpochsContext(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load epochs context: %v", err)
+				}
+			}
+			if i, ok := epc.ValidatorPubkeyCache.ValidatorIndex(common.BLSPubkey(x)); ok && uint64(i) < count {
+				out = append(out, i)
+			}
+		}
+	}
+	return out, nil
+}
+
+func loadValidators(ctx context.Context, backend *BeaconBackend, entry beacon.ChainEntry, ids []eth2api.ValidatorId) ([]eth2api.ValidatorResponse, error) {
+	state, err := entry.State(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %v", err)
+	}
+	indices, err := resolveValidatorIndices(ctx, entry, state, ids)
+	if err != nil {
+		return nil, err
+	}
+	vals, err := state.Validators()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load validators: %v", err)
+	}
+	balances, err := state.Balances()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load balances: %v", err)
+	}
+	epoch := backend.Spec.SlotToEpoch(entry.Step().Slot())
+	out := make([]eth2api.ValidatorResponse, 0, len(indices))
+	for _, i := range indices {
+		v, err := vals.Validator(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load validator %d: %v", i, err)
+		}
+		bal, err := balances.GetBalance(i)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load balance of validator %d: %v", i, err)
+		}
+		res := eth2api.ValidatorResponse{Index: i, Balance: bal}
+		if err := flattenValidator(v, &res.Validator); err != nil {
+			return nil, fmt.Errorf("failed to load validator %d: %v", i, err)
+		}
+		res.Status = validatorStatus(&res.Validator, bal, epoch)
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func flattenValidator(v common.Validator, dst *phase0.Validator) (err error) {
+	if dst.Pubkey, err = v.Pubkey(); err != nil {
+		return err
+	}
+	if dst.WithdrawalCredentials, err = v.WithdrawalCredentials(); err != nil {
+		return err
+	}
+	var flat common.FlatValidator
+	if err := v.Flatten(&flat); err != nil {
+		return err
+	}
+	dst.EffectiveBalance = flat.EffectiveBalance
+	dst.Slashed = flat.Slashed
+	dst.ActivationEligibilityEpoch = flat.ActivationEligibilityEpoch
+	dst.ActivationEpoch = flat.ActivationEpoch
+	dst.ExitEpoch = flat.ExitEpoch
+	dst.WithdrawableEpoch = flat.WithdrawableEpoch
+	return nil
+}
+
+// validatorStatus computes the status of the validator at the given epoch, as standardized in the API spec.
+func validatorStatus(v *phase0.Validator, balance common.Gwei, epoch common.Epoch) eth2api.ValidatorStatus {
+	switch {
+	case v.ActivationEpoch > epoch:
+		if v.ActivationEligibilityEpoch == common.FAR_FUTURE_EPOCH {
+			return eth2api.ValidatorStatusPendingInitialized
+		}
+		return eth2api.ValidatorStatusPendingQueued
+	case epoch < v.ExitEpoch:
+		if v.ExitEpoch == common.FAR_FUTURE_EPOCH {
+			return eth2api.ValidatorStatusActiveOngoing
+		}
+		if v.Slashed {
+			return eth2api.ValidatorStatusActiveSlashed
+		}
+		return eth2api.ValidatorStatusActiveExiting
+	case epoch < v.WithdrawableEpoch:
+		if v.Slashed {
+			return eth2api.ValidatorStatusExitedSlashed
+		}
+		return eth2api.ValidatorStatusExitedUnslashed
+	default:
+		if balance != 0 {
+			return eth2api.ValidatorStatusWithdrawalPossible
+		}
+		return eth2api.ValidatorStatusWithdrawalDone
+	}
+}
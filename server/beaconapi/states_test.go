@@ -0,0 +1,185 @@
+package beaconapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+func TestValidatorStatus(t *testing.T) {
+	far := common.FAR_FUTURE_EPOCH
+	cases := []struct {
+		v       phase0.Validator
+		balance common.Gwei
+		status  eth2api.ValidatorStatus
+	}{
+		{phase0.Validator{ActivationEligibilityEpoch: far, ActivationEpoch: far, ExitEpoch: far, WithdrawableEpoch: far}, 32, eth2api.ValidatorStatusPendingInitialized},
+		{phase0.Validator{ActivationEligibilityEpoch: 5, ActivationEpoch: 11, ExitEpoch: far, WithdrawableEpoch: far}, 32, eth2api.ValidatorStatusPendingQueued},
+		{phase0.Validator{ActivationEpoch: 10, ExitEpoch: far, WithdrawableEpoch: far}, 32, eth2api.ValidatorStatusActiveOngoing},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 12, WithdrawableEpoch: 20}, 32, eth2api.ValidatorStatusActiveExiting},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 12, WithdrawableEpoch: 20, Slashed: true}, 32, eth2api.ValidatorStatusActiveSlashed},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 10, WithdrawableEpoch: 20}, 32, eth2api.ValidatorStatusExitedUnslashed},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 10, WithdrawableEpoch: 20, Slashed: true}, 32, eth2api.ValidatorStatusExitedSlashed},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 5, WithdrawableEpoch: 10}, 32, eth2api.ValidatorStatusWithdrawalPossible},
+		{phase0.Validator{ActivationEpoch: 2, ExitEpoch: 5, WithdrawableEpoch: 10}, 0, eth2api.ValidatorStatusWithdrawalDone},
+	}
+	for i, c := range cases {
+		if got := validatorStatus(&c.v, c.balance, 10); got != c.status {
+			t.Errorf("case %d: got %q, expected %q", i, got, c.status)
+		}
+	}
+	if !eth2api.ValidatorStatusActiveExiting.Matches("active") || eth2api.ValidatorStatusPendingQueued.Matches("active") {
+		t.Error("unexpected generic status matching")
+	}
+}
+
+func TestStateRoutes(t *testing.T) {
+	backend, _ := newTestBackend()
+	pubkeys := addTestStates(t, backend)
+	spec := backend.Spec
+	cli := serve(t, StateRoot(backend), Fork(backend), FinalityCheckpoints(backend), EpochCommittees(backend),
+		SyncCommittees(backend), StateValidator(backend), StateValidators(backend), StateValidatorBalances(backend))
+	ctx := context.Background()
+
+	chain := backend.Chain.(*testChain)
+	genesisRoot, _ := chain.entries[0].StateRoot()
+	headRoot, _ := chain.entries[1].StateRoot()
+	for _, c := range []struct {
+		id     eth2api.StateId
+		root   common.Root
+		exists bool
+	}{
+		{eth2api.StateHead, headRoot, true},
+		{eth2api.StateGenesis, genesisRoot, true},
+		{eth2api.StateFinalized, genesisRoot, true},
+		{eth2api.StateJustified, genesisRoot, true},
+		{eth2api.StateIdSlot(0), genesisRoot, true},
+		{eth2api.StateIdSlot(8), headRoot, true},
+		{eth2api.StateIdRoot(headRoot), headRoot, true},
+		{eth2api.StateIdRoot{0x13}, common.Root{}, false},
+		{eth2api.StateIdSlot(3), common.Root{}, false},
+	} {
+		root, exists, err := beaconapi.StateRoot(ctx, cli, c.id)
+		if err != nil || exists != c.exists || root != c.root {
+			t.Errorf("state %s: unexpected root %s, exists %v, err %v", c.id.StateId(), root, exists, err)
+		}
+	}
+
+	var fork common.Fork
+	if exists, err := beaconapi.Fork(ctx, cli, eth2api.StateHead, &fork); err != nil || !exists {
+		t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+	}
+	if fork.PreviousVersion != spec.GENESIS_FORK_VERSION || fork.CurrentVersion != spec.ALTAIR_FORK_VERSION || fork.Epoch != 1 {
+		t.Errorf("unexpected fork: %v", fork)
+	}
+	var checkpoints eth2api.FinalityCheckpoints
+	if exists, err := beaconapi.FinalityCheckpoints(ctx, cli, eth2api.StateGenesis, &checkpoints); err != nil || !exists {
+		t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+	}
+	if checkpoints != (eth2api.FinalityCheckpoints{}) {
+		t.Errorf("unexpected finality checkpoints: %v", checkpoints)
+	}
+
+	// minimal preset: 8 slots per epoch, with 2 committees per slot for 64 validators
+	epoch, index, slot := common.Epoch(0), common.CommitteeIndex(1), common.Slot(3)
+	for _, c := range []struct {
+		epoch      *common.Epoch
+		index      *common.CommitteeIndex
+		slot       *common.Slot
+		committees int
+		validators int
+	}{
+		{nil, nil, nil, 16, testValidatorCount},
+		{&epoch, nil, &slot, 2, testValidatorCount / 8},
+		{nil, &index, nil, 8, testValidatorCount / 2},
+		{nil, &index, &slot, 1, testValidatorCount / 16},
+	} {
+		var committees []eth2api.Committee
+		if exists, err := beaconapi.EpochCommittees(ctx, cli, eth2api.StateGenesis, c.epoch, c.index, c.slot, &committees); err != nil || !exists {
+			t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+		}
+		validators := 0
+		for _, comm := range committees {
+			validators += len(comm.Validators)
+			if (c.index != nil && comm.Index != *c.index) || (c.slot != nil && comm.Slot != *c.slot) {
+				t.Errorf("unexpected committee %d at slot %d", comm.Index, comm.Slot)
+			}
+		}
+		if len(committees) != c.committees || validators != c.validators {
+			t.Errorf("unexpected %d committees with %d validators, expected %d with %d", len(committees), validators, c.committees, c.validators)
+		}
+	}
+
+	var syncCommittees eth2api.SyncCommittees
+	if exists, err := beaconapi.SyncCommittees(ctx, cli, eth2api.StateHead, nil, &syncCommittees); err != nil || !exists {
+		t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+	}
+	if uint64(len(syncCommittees.Validators)) != uint64(spec.SYNC_COMMITTEE_SIZE) || len(syncCommittees.ValidatorAggregates) != common.SYNC_COMMITTEE_SUBNET_COUNT {
+		t.Errorf("unexpected sync committees: %d validators, %d aggregates", len(syncCommittees.Validators), len(syncCommittees.ValidatorAggregates))
+	}
+
+	var validator eth2api.ValidatorResponse
+	for _, id := range []eth2api.ValidatorId{eth2api.ValidatorIdIndex(5), eth2api.ValidatorIdPubkey(pubkeys[5])} {
+		if exists, err := beaconapi.StateValidator(ctx, cli, eth2api.StateHead, id, &validator); err != nil || !exists {
+			t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+		}
+		if validator.Index != 5 || validator.Validator.Pubkey != pubkeys[5] || validator.Balance != spec.MAX_EFFECTIVE_BALANCE || validator.Status != eth2api.ValidatorStatusActiveOngoing {
+			t.Errorf("unexpected validator: %v", validator)
+		}
+	}
+	if exists, err := beaconapi.StateValidator(ctx, cli, eth2api.StateHead, eth2api.ValidatorIdIndex(999), &validator); err != nil || exists {
+		t.Errorf("expected unknown validator, got exists %v, err %v", exists, err)
+	}
+	ids := []eth2api.ValidatorId{eth2api.ValidatorIdIndex(1), eth2api.ValidatorIdPubkey(pubkeys[2]), eth2api.ValidatorIdIndex(999)}
+	for _, c := range []struct {
+		ids    []eth2api.ValidatorId
+		status []eth2api.ValidatorStatus
+		count  int
+	}{
+		{nil, nil, testValidatorCount},
+		{ids, nil, 2},
+		{nil, []eth2api.ValidatorStatus{"active"}, testValidatorCount},
+		{ids, []eth2api.ValidatorStatus{eth2api.ValidatorStatusPendingQueued, "exited"}, 0},
+	} {
+		var validators []eth2api.ValidatorResponse
+		if exists, err := beaconapi.StateValidators(ctx, cli, eth2api.StateHead, c.ids, c.status, &validators); err != nil || !exists {
+			t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+		}
+		if len(validators) != c.count {
+			t.Errorf("unexpected validators count %d, expected %d", len(validators), c.count)
+		}
+	}
+	var balances []eth2api.ValidatorBalanceResponse
+	if exists, err := beaconapi.StateValidatorBalances(ctx, cli, eth2api.StateFinalized, ids, &balances); err != nil || !exists {
+		t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+	}
+	if len(balances) != 2 || balances[0].Index != 1 || balances[1].Index != 2 || balances[1].Balance != spec.MAX_EFFECTIVE_BALANCE {
+		t.Errorf("unexpected balances: %v", balances)
+	}
+
+	for _, c := range []struct {
+		path string
+		code uint
+	}{
+		{"/eth/v1/beacon/states/bad/root", 400},
+		{"/eth/v1/beacon/states/3/fork", 404},
+		{"/eth/v1/beacon/states/head/committees?epoch=x", 400},
+		{"/eth/v1/beacon/states/head/committees?epoch=1&slot=3", 400},
+		{"/eth/v1/beacon/states/head/committees?epoch=5", 400},
+		{"/eth/v1/beacon/states/genesis/sync_committees", 400},
+		{"/eth/v1/beacon/states/head/sync_committees?epoch=100", 400},
+		{"/eth/v1/beacon/states/head/validators/foo", 400},
+		{"/eth/v1/beacon/states/head/validators/999", 404},
+		{"/eth/v1/beacon/states/head/validators?id=foo", 400},
+		{"/eth/v1/beacon/states/finalized/validator_balances?id=1,foo", 400},
+		{"/eth/v1/beacon/states/unknown/finality_checkpoints", 400},
+	} {
+		if code, err := cli.Request(ctx, eth2api.PlainGET(c.path)).Decode(nil); code != c.code {
+			t.Errorf("%s: unexpected code %d, expected %d: %v", c.path, code, c.code, err)
+		}
+	}
+}
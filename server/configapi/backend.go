@@ -0,0 +1,35 @@
+package configapi
+
+import (
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+type ConfigBackend struct {
+	Spec *common.Spec
+	// DepositContract is the deposit contract of the chain, not part of the spec config.
+	DepositContract eth2api.DepositContractResponse
+}
+
+// Forks lists the forks of the spec config that are scheduled, i.e. do not have a far-future epoch, in order.
+// The genesis fork is included, with the same previous and current version.
+func (backend *ConfigBackend) Forks() []common.Fork {
+	spec := backend.Spec
+	forks := []common.Fork{{
+		PreviousVersion: spec.GENESIS_FORK_VERSION,
+		CurrentVersion:  spec.GENESIS_FORK_VERSION,
+		Epoch:           common.GENESIS_EPOCH,
+	}}
+	for _, next := range eth2api.Forks[1:] {
+		epoch := next.Epoch(spec)
+		if epoch == common.FAR_FUTURE_EPOCH {
+			break
+		}
+		forks = append(forks, common.Fork{
+			PreviousVersion: forks[len(forks)-1].CurrentVersion,
+			CurrentVersion:  next.Version(spec),
+			Epoch:           epoch,
+		})
+	}
+	return forks
+}
@@ -0,0 +1,58 @@
+package configapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	clientapi "github.com/protolambda/eth2api/client/configapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/configs"
+)
+
+func TestConfigRoutes(t *testing.T) {
+	spec := *configs.Mainnet
+	spec.ELECTRA_FORK_EPOCH = common.FAR_FUTURE_EPOCH
+	backend := &ConfigBackend{
+		Spec:            &spec,
+		DepositContract: eth2api.DepositContractResponse{ChainID: 1, Address: common.Eth1Address{0x42}},
+	}
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(Spec(backend))
+	router.AddRoute(ForkSchedule(backend))
+	router.AddRoute(DepositContract(backend))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	var gotSpec common.Spec
+	if err := clientapi.Spec(ctx, cli, &gotSpec); err != nil {
+		t.Fatal(err)
+	}
+	if gotSpec.CONFIG_NAME != spec.CONFIG_NAME || gotSpec.SLOTS_PER_EPOCH != spec.SLOTS_PER_EPOCH ||
+		gotSpec.CAPELLA_FORK_VERSION != spec.CAPELLA_FORK_VERSION {
+		t.Fatal("spec did not round-trip")
+	}
+
+	var forks []common.Fork
+	if err := clientapi.ForkSchedule(ctx, cli, &forks); err != nil {
+		t.Fatal(err)
+	}
+	if len(forks) != 5 {
+		t.Fatalf("expected phase0 up to deneb, got %d forks", len(forks))
+	}
+	if last := forks[4]; last.PreviousVersion != spec.CAPELLA_FORK_VERSION ||
+		last.CurrentVersion != spec.DENEB_FORK_VERSION || last.Epoch != spec.DENEB_FORK_EPOCH {
+		t.Fatalf("unexpected deneb fork: %v", last)
+	}
+
+	var deposit eth2api.DepositContractResponse
+	if err := clientapi.DepositContract(ctx, cli, &deposit); err != nil {
+		t.Fatal(err)
+	}
+	if deposit != backend.DepositContract {
+		t.Fatalf("unexpected deposit contract: %v", deposit)
+	}
+}
@@ -0,0 +1,15 @@
+package configapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves all scheduled upcoming forks this node is aware of.
+func ForkSchedule(backend *ConfigBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/config/fork_schedule",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			return eth2api.RespondOK(eth2api.Wrap(backend.Forks()))
+		})
+}
@@ -0,0 +1,15 @@
+package configapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves the specification configuration used on this node.
+func Spec(backend *ConfigBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/config/spec",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			return eth2api.RespondOK(eth2api.Wrap(backend.Spec))
+		})
+}
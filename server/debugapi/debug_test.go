@@ -0,0 +1,144 @@
+package debugapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	client "github.com/protolambda/eth2api/client/debugapi"
+	"github.com/protolambda/eth2api/server/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/zrnt/eth2/configs"
+)
+
+// testChain has a phase0 genesis entry and an altair head entry.
+type testChain struct {
+	beacon.Chain
+	entries []*testEntry
+}
+
+func (c *testChain) Search(parentRoot *common.Root, slot *common.Slot) ([]beacon.SearchEntry, error) {
+	return []beacon.SearchEntry{{ChainEntry: c.entries[1], Canonical: true}}, nil
+}
+
+func (c *testChain) ByCanonStep(step common.Step) (beacon.ChainEntry, bool) {
+	for _, e := range c.entries {
+		if e.Step() == step {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func (c *testChain) Head() (beacon.ChainEntry, error) {
+	return c.entries[1], nil
+}
+
+type testEntry struct {
+	beacon.ChainEntry
+	slot  common.Slot
+	root  common.Root
+	state common.BeaconState
+}
+
+func (e *testEntry) Step() common.Step {
+	return common.AsStep(e.slot, true)
+}
+
+func (e *testEntry) BlockRoot() (common.Root, error) {
+	return e.root, nil
+}
+
+func (e *testEntry) State(ctx context.Context) (common.BeaconState, error) {
+	return e.state, nil
+}
+
+func TestDebugRoutes(t *testing.T) {
+	spec := configs.Minimal
+	genesis, err := phase0.AsBeaconStateView(phase0.BeaconStateType(spec).New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := altair.AsBeaconStateView(altair.BeaconStateType(spec).New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := head.SetSlot(8); err != nil {
+		t.Fatal(err)
+	}
+	backend := &beaconapi.BeaconBackend{Spec: spec, Chain: &testChain{entries: []*testEntry{
+		{slot: 0, root: common.Root{0x01}, state: genesis},
+		{slot: 8, root: common.Root{0x02}, state: head},
+	}}}
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(BeaconChainHeads(backend))
+	router.AddRoute(BeaconState(backend))
+	router.AddRoute(BeaconStateV2(backend))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	var heads []eth2api.ChainHead
+	if err := client.BeaconChainHeads(ctx, cli, &heads); err != nil {
+		t.Fatal(err)
+	}
+	if len(heads) != 1 || heads[0] != (eth2api.ChainHead{Root: common.Root{0x02}, Slot: 8}) {
+		t.Fatalf("unexpected heads: %v", heads)
+	}
+
+	var phase0State phase0.BeaconState
+	if exists, err := client.BeaconState(ctx, cli, eth2api.StateGenesis, &phase0State); err != nil || !exists {
+		t.Fatalf("unexpected result: exists %v, err %v", exists, err)
+	}
+	// later forks are only served by the v2 route
+	if exists, err := client.BeaconState(ctx, cli, eth2api.StateHead, &phase0State); err == nil || !exists {
+		t.Fatalf("expected bad input for altair state, got exists %v, err %v", exists, err)
+	}
+
+	for _, c := range []struct {
+		id      eth2api.StateId
+		version string
+		slot    common.Slot
+	}{
+		{eth2api.StateGenesis, "phase0", 0},
+		{eth2api.StateHead, "altair", 8},
+	} {
+		resp := cli.Request(ctx, eth2api.FmtGET("/eth/v2/debug/beacon/states/%s", c.id.StateId()))
+		var state eth2api.VersionedBeaconState
+		if _, err := resp.Decode(&state); err != nil {
+			t.Fatal(err)
+		}
+		if v := resp.Headers()[eth2api.ConsensusVersionHeader]; v != c.version {
+			t.Errorf("unexpected version header: %q, expected %q", v, c.version)
+		}
+		if state.Version != c.version {
+			t.Errorf("unexpected version: %q, expected %q", state.Version, c.version)
+		}
+		tree, err := state.Tree(spec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if slot, err := tree.Slot(); err != nil || slot != c.slot {
+			t.Errorf("unexpected slot %d, expected %d: %v", slot, c.slot, err)
+		}
+	}
+
+	for _, c := range []struct {
+		path string
+		code uint
+	}{
+		{"/eth/v1/debug/beacon/states/bad", 400},
+		{"/eth/v1/debug/beacon/states/3", 404},
+		{"/eth/v2/debug/beacon/states/bad", 400},
+		{"/eth/v2/debug/beacon/states/3", 404},
+	} {
+		if code, err := cli.Request(ctx, eth2api.PlainGET(c.path)).Decode(nil); code != c.code {
+			t.Errorf("%s: unexpected code %d, expected %d: %v", c.path, code, c.code, err)
+		}
+	}
+}
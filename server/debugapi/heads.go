@@ -0,0 +1,29 @@
+package debugapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/server/beaconapi"
+)
+
+// Serves all possible chain heads (leaves of fork choice tree).
+func BeaconChainHeads(backend *beaconapi.BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/debug/beacon/heads",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			entries, err := backend.Chain.Search(nil, nil)
+			if err != nil {
+				return eth2api.RespondInternalError(fmt.Errorf("failed to search chain heads: %v", err))
+			}
+			heads := make([]eth2api.ChainHead, 0, len(entries))
+			for _, entry := range entries {
+				root, err := entry.BlockRoot()
+				if err != nil {
+					return eth2api.RespondInternalError(fmt.Errorf("failed to load head block root: %v", err))
+				}
+				heads = append(heads, eth2api.ChainHead{Root: root, Slot: entry.Step().Slot()})
+			}
+			return eth2api.RespondOK(eth2api.Wrap(heads))
+		})
+}
@@ -0,0 +1,57 @@
+package debugapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/server/beaconapi"
+)
+
+func versionedState(ctx context.Context, backend *beaconapi.BeaconBackend, req eth2api.Request) (*eth2api.VersionedBeaconState, eth2api.PreparedResponse) {
+	stateId, err := eth2api.ParseStateId(req.Param("stateId"))
+	if err != nil {
+		return nil, eth2api.RespondBadInput(err)
+	}
+	entry, ok := backend.StateLookup(stateId)
+	if !ok {
+		return nil, eth2api.RespondNotFound("State not found")
+	}
+	state, err := entry.State(ctx)
+	if err != nil {
+		return nil, eth2api.RespondInternalError(fmt.Errorf("failed to load state: %v", err))
+	}
+	var out eth2api.VersionedBeaconState
+	if err := out.FromTree(backend.Spec, state); err != nil {
+		return nil, eth2api.RespondInternalError(fmt.Errorf("failed to convert state: %v", err))
+	}
+	return &out, nil
+}
+
+// Serves the phase0 BeaconState object for given stateId.
+// States of later forks are only available through BeaconStateV2.
+func BeaconState(backend *beaconapi.BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/debug/beacon/states/:stateId",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			state, errResp := versionedState(ctx, backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			if state.Version != "phase0" {
+				return eth2api.RespondBadInput(fmt.Errorf("state is not a phase0 state, but %s, use the v2 endpoint", state.Version))
+			}
+			return eth2api.RespondOK(eth2api.Wrap(state.Data))
+		})
+}
+
+// Serves the versioned BeaconState object for given stateId.
+func BeaconStateV2(backend *beaconapi.BeaconBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v2/debug/beacon/states/:stateId",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			state, errResp := versionedState(ctx, backend, req)
+			if errResp != nil {
+				return errResp
+			}
+			return eth2api.RespondOKVersioned(state)
+		})
+}
@@ -0,0 +1,77 @@
+package eventsapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/server/beaconapi"
+)
+
+var knownTopics = map[eth2api.EventTopic]struct{}{
+	eth2api.EventTopicHead:                 {},
+	eth2api.EventTopicBlock:                {},
+	eth2api.EventTopicAttestation:          {},
+	eth2api.EventTopicVoluntaryExit:        {},
+	eth2api.EventTopicFinalizedCheckpoint:  {},
+	eth2api.EventTopicChainReorg:           {},
+	eth2api.EventTopicContributionAndProof: {},
+	eth2api.EventTopicPayloadAttributes:    {},
+	eth2api.EventTopicBlobSidecar:          {},
+}
+
+// Serves the event stream of the node, with the events of the requested topics.
+// The stream ends when the client disconnects, or when the client falls behind and is dropped by the event bus.
+// Clients that reconnect with a Last-Event-ID header resume after that event, if the event bus can replay it.
+// Otherwise, e.g. after a restart of the node, the stream continues with live events only,
+// and the events in between are lost.
+func Events(backend *beaconapi.BeaconBackend) eth2api.StreamRoute {
+	return eth2api.MakeStreamRoute("/eth/v1/events",
+		func(ctx context.Context, req eth2api.Request, sink eth2api.EventSink) eth2api.PreparedResponse {
+			topicVals, ok := req.Query("topics")
+			if !ok || len(topicVals) == 0 {
+				return eth2api.RespondBadInput(errors.New("missing topics query param"))
+			}
+			var topics []eth2api.EventTopic
+			for _, v := range topicVals {
+				for _, t := range strings.Split(v, ",") {
+					topic := eth2api.EventTopic(strings.TrimSpace(t))
+					if _, ok := knownTopics[topic]; !ok {
+						return eth2api.RespondBadInput(fmt.Errorf("unknown event topic: %q", topic))
+					}
+					topics = append(topics, topic)
+				}
+			}
+			if backend.Events == nil {
+				return eth2api.RespondNotImplemented("event stream is not supported")
+			}
+			var sub beaconapi.EventSubscription
+			if lastEventID, ok := req.Header("Last-Event-ID"); ok && lastEventID != "" {
+				if replay, ok := backend.Events.(beaconapi.EventReplayBus); ok {
+					sub, _ = replay.SubscribeAfter(topics, lastEventID)
+				}
+			}
+			if sub == nil {
+				sub = backend.Events.Subscribe(topics)
+			}
+			defer sub.Close()
+			if err := sink.Open(); err != nil {
+				return nil
+			}
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case ev, ok := <-sub.Events():
+					if !ok {
+						return nil
+					}
+					if err := sink.Send(ev); err != nil {
+						return nil
+					}
+				}
+			}
+		})
+}
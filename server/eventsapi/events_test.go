@@ -0,0 +1,264 @@
+package eventsapi
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/protolambda/eth2api"
+	clientevents "github.com/protolambda/eth2api/client/eventsapi"
+	"github.com/protolambda/eth2api/server/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+func TestEvents(t *testing.T) {
+	feed := new(EventFeed)
+	router := eth2api.NewHttpRouter()
+	router.AddStreamRoute(Events(&beaconapi.BeaconBackend{Events: feed}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan *clientevents.Event, 10)
+	done := make(chan error)
+	go func() {
+		done <- clientevents.Events(ctx, cli, []eth2api.EventTopic{eth2api.EventTopicHead, eth2api.EventTopicChainReorg}, events)
+	}()
+	for feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	feed.PublishBlock(&eth2api.BlockEvent{Slot: 1}) // filtered out
+	feed.PublishHead(&eth2api.HeadEvent{Slot: 2})
+	feed.PublishChainReorg(&eth2api.ChainReorgEvent{Slot: 3, Depth: 1})
+
+	head := <-events
+	if ev, ok := head.Data.(*eth2api.HeadEvent); !ok || ev.Slot != 2 || head.ID != "2" {
+		t.Fatalf("unexpected head event: %v", head)
+	}
+	reorg := <-events
+	if ev, ok := reorg.Data.(*eth2api.ChainReorgEvent); !ok || ev.Slot != 3 || ev.Depth != 1 {
+		t.Fatalf("unexpected reorg event: %v", reorg)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("unexpected subscription end: %v", err)
+	}
+	// the server cleans up the subscription after the client disconnects
+	for feed.Subscribers() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestEventsUnknownTopic(t *testing.T) {
+	router := eth2api.NewHttpRouter()
+	router.AddStreamRoute(Events(&beaconapi.BeaconBackend{Events: new(EventFeed)}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	err := clientevents.Subscribe(context.Background(), cli, []eth2api.EventTopic{"foo"}, func(ev *clientevents.Event) {})
+	if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 400 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestEventFeedSlowSubscriber(t *testing.T) {
+	feed := &EventFeed{BufferSize: 2}
+	slow := feed.Subscribe([]eth2api.EventTopic{eth2api.EventTopicHead})
+	for i := 0; i < 3; i++ {
+		feed.PublishHead(&eth2api.HeadEvent{})
+	}
+	if n := feed.Subscribers(); n != 0 {
+		t.Fatalf("expected slow subscriber to be dropped, got %d subscribers", n)
+	}
+	// buffered events are still delivered, then the channel is closed
+	count := 0
+	for range slow.Events() {
+		count++
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 buffered events, got %d", count)
+	}
+	slow.Close()
+}
+
+func TestEventsNotImplemented(t *testing.T) {
+	router := eth2api.NewHttpRouter()
+	router.AddStreamRoute(Events(&beaconapi.BeaconBackend{}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	req := eth2api.QueryGET(eth2api.Query{"topics": "head"}, "/eth/v1/events")
+	if code, err := cli.Request(context.Background(), req).Decode(nil); code != 501 || err == nil {
+		t.Fatalf("expected not implemented, got %d: %v", code, err)
+	}
+}
+
+func TestEventsResume(t *testing.T) {
+	feed := &EventFeed{HistorySize: 4}
+	for i := 1; i <= 5; i++ {
+		feed.PublishHead(&eth2api.HeadEvent{Slot: common.Slot(i)})
+	}
+	feed.PublishBlock(&eth2api.BlockEvent{Slot: 6})
+	newClient := func(bus beaconapi.EventBus) *eth2api.Eth2HttpClient {
+		router := eth2api.NewHttpRouter()
+		router.AddStreamRoute(Events(&beaconapi.BeaconBackend{Events: bus}))
+		srv := httptest.NewServer(router)
+		t.Cleanup(srv.Close)
+		return &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	}
+	cli := newClient(feed)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan *clientevents.Event, 10)
+	sub := &clientevents.Subscriber{
+		Client:      cli,
+		Topics:      []eth2api.EventTopic{eth2api.EventTopicHead},
+		OnEvent:     func(ev *clientevents.Event) { events <- ev },
+		LastEventID: "3",
+	}
+	done := make(chan error)
+	go func() {
+		done <- sub.Run(ctx)
+	}()
+	for feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	feed.PublishHead(&eth2api.HeadEvent{Slot: 7})
+	for _, expected := range []common.Slot{4, 5, 7} {
+		ev := <-events
+		if head, ok := ev.Data.(*eth2api.HeadEvent); !ok || head.Slot != expected || ev.ID != fmt.Sprint(expected) {
+			t.Fatalf("unexpected event: %v, expected head of slot %d", ev, expected)
+		}
+	}
+	cancel()
+	<-done
+	for feed.Subscribers() != 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the events after 1 are no longer all available, and an event bus without replay cannot resume at all:
+	// the stream continues with live events instead
+	for _, c := range []struct {
+		cli         eth2api.Client
+		lastEventID string
+	}{
+		{cli, "1"},
+		{cli, "foo"},
+		{newClient(struct{ beaconapi.EventBus }{feed}), "7"},
+	} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		events := make(chan *clientevents.Event, 10)
+		sub := &clientevents.Subscriber{Client: c.cli, Topics: []eth2api.EventTopic{eth2api.EventTopicHead},
+			OnEvent: func(ev *clientevents.Event) { events <- ev }, LastEventID: c.lastEventID}
+		done := make(chan error)
+		go func() {
+			done <- sub.Run(ctx)
+		}()
+		for feed.Subscribers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		feed.PublishHead(&eth2api.HeadEvent{Slot: 8})
+		select {
+		case ev := <-events:
+			if head, ok := ev.Data.(*eth2api.HeadEvent); !ok || head.Slot != 8 {
+				t.Fatalf("last event %q: unexpected event: %v", c.lastEventID, ev)
+			}
+		case err := <-done:
+			t.Fatalf("last event %q: unexpected subscription end: %v", c.lastEventID, err)
+		}
+		cancel()
+		<-done
+		for feed.Subscribers() != 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// restartBus is an event bus of which the feed can be replaced, like the feed of a restarted node.
+type restartBus struct {
+	mu   sync.Mutex
+	feed *EventFeed
+}
+
+func (b *restartBus) current() *EventFeed {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.feed
+}
+
+func (b *restartBus) restart() *EventFeed {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.feed = new(EventFeed)
+	return b.feed
+}
+
+func (b *restartBus) Subscribe(topics []eth2api.EventTopic) beaconapi.EventSubscription {
+	return b.current().Subscribe(topics)
+}
+
+func (b *restartBus) SubscribeAfter(topics []eth2api.EventTopic, lastEventID string) (beaconapi.EventSubscription, bool) {
+	return b.current().SubscribeAfter(topics, lastEventID)
+}
+
+func TestEventsRestart(t *testing.T) {
+	bus := &restartBus{feed: new(EventFeed)}
+	router := eth2api.NewHttpRouter()
+	router.AddStreamRoute(Events(&beaconapi.BeaconBackend{Events: bus}))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events := make(chan *clientevents.Event, 10)
+	sub := &clientevents.Subscriber{
+		Client:         cli,
+		Topics:         []eth2api.EventTopic{eth2api.EventTopicHead},
+		OnEvent:        func(ev *clientevents.Event) { events <- ev },
+		ReconnectDelay: 10 * time.Millisecond,
+	}
+	done := make(chan error)
+	go func() {
+		done <- sub.Run(ctx)
+	}()
+	feed := bus.current()
+	for feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	for i := 1; i <= 3; i++ {
+		feed.PublishHead(&eth2api.HeadEvent{Slot: common.Slot(i)})
+	}
+	for i := 1; i <= 3; i++ {
+		<-events
+	}
+
+	// after the restart the new feed does not know the last event ID of the subscriber
+	feed = bus.restart()
+	srv.CloseClientConnections()
+	for feed.Subscribers() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	feed.PublishHead(&eth2api.HeadEvent{Slot: 4})
+	select {
+	case ev := <-events:
+		if head, ok := ev.Data.(*eth2api.HeadEvent); !ok || head.Slot != 4 || ev.ID != "1" {
+			t.Fatalf("unexpected event: %v", ev)
+		}
+	case err := <-done:
+		t.Fatalf("unexpected subscription end: %v", err)
+	}
+	cancel()
+	<-done
+}
@@ -0,0 +1,163 @@
+package eventsapi
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/server/beaconapi"
+)
+
+// DefaultBufferSize is the number of events buffered per subscriber, if the EventFeed does not specify it.
+const DefaultBufferSize = 64
+
+// DefaultHistorySize is the number of recent events kept for replay, if the EventFeed does not specify it.
+const DefaultHistorySize = 256
+
+// EventFeed is an EventBus, for backends to publish their events to.
+//
+// Publishing never blocks: events are buffered per subscriber,
+// and subscribers that fall behind by more than the buffer size are dropped, which ends their stream.
+// Clients can then reconnect to continue after the last event they received,
+// as long as the events after it are still in the history of recent events,
+// and continue with live events otherwise.
+type EventFeed struct {
+	// Number of events to buffer per subscriber. Defaults to DefaultBufferSize.
+	BufferSize int
+	// Number of recent events to keep, to replay to reconnecting subscribers. Defaults to DefaultHistorySize.
+	HistorySize int
+
+	mu   sync.Mutex
+	subs map[*feedSubscription]struct{}
+	// recent events of all topics, the last event has ID nextID
+	history []*eth2api.ServerEvent
+	nextID  uint64
+}
+
+var _ beaconapi.EventReplayBus = (*EventFeed)(nil)
+
+type feedSubscription struct {
+	feed   *EventFeed
+	topics map[eth2api.EventTopic]struct{}
+	ch     chan *eth2api.ServerEvent
+	// closed is guarded by the feed mutex
+	closed bool
+}
+
+func (s *feedSubscription) Events() <-chan *eth2api.ServerEvent {
+	return s.ch
+}
+
+func (s *feedSubscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+	s.feed.remove(s)
+}
+
+func (f *EventFeed) remove(s *feedSubscription) {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	delete(f.subs, s)
+	close(s.ch)
+}
+
+// subscribe adds a subscription, with the replayed events of its topics already buffered.
+// The feed mutex must be held.
+func (f *EventFeed) subscribe(topics []eth2api.EventTopic, replay []*eth2api.ServerEvent) *feedSubscription {
+	size := f.BufferSize
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	s := &feedSubscription{
+		feed:   f,
+		topics: make(map[eth2api.EventTopic]struct{}, len(topics)),
+		ch:     make(chan *eth2api.ServerEvent, size+len(replay)),
+	}
+	for _, t := range topics {
+		s.topics[t] = struct{}{}
+	}
+	for _, ev := range replay {
+		if _, ok := s.topics[eth2api.EventTopic(ev.Event)]; ok {
+			s.ch <- ev
+		}
+	}
+	if f.subs == nil {
+		f.subs = make(map[*feedSubscription]struct{})
+	}
+	f.subs[s] = struct{}{}
+	return s
+}
+
+func (f *EventFeed) Subscribe(topics []eth2api.EventTopic) beaconapi.EventSubscription {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.subscribe(topics, nil)
+}
+
+// SubscribeAfter subscribes to the events after the event with the given ID,
+// if no events after it were dropped from the history yet.
+func (f *EventFeed) SubscribeAfter(topics []eth2api.EventTopic, lastEventID string) (beaconapi.EventSubscription, bool) {
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil {
+		return nil, false
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	// the history has the events with IDs (oldest, nextID]
+	oldest := f.nextID - uint64(len(f.history))
+	if last < oldest || last > f.nextID {
+		return nil, false
+	}
+	return f.subscribe(topics, f.history[last-oldest:]), true
+}
+
+// Subscribers returns the number of active subscriptions.
+func (f *EventFeed) Subscribers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.subs)
+}
+
+// Publish sends the event to all subscribers of the topic, and drops any subscribers that fell behind.
+func (f *EventFeed) Publish(topic eth2api.EventTopic, data interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextID++
+	ev := &eth2api.ServerEvent{ID: strconv.FormatUint(f.nextID, 10), Event: string(topic), Data: data}
+	historySize := f.HistorySize
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	f.history = append(f.history, ev)
+	if len(f.history) > historySize {
+		f.history = f.history[len(f.history)-historySize:]
+	}
+	for s := range f.subs {
+		if _, ok := s.topics[topic]; !ok {
+			continue
+		}
+		select {
+		case s.ch <- ev:
+		default:
+			f.remove(s)
+		}
+	}
+}
+
+func (f *EventFeed) PublishHead(ev *eth2api.HeadEvent) {
+	f.Publish(eth2api.EventTopicHead, ev)
+}
+
+func (f *EventFeed) PublishBlock(ev *eth2api.BlockEvent) {
+	f.Publish(eth2api.EventTopicBlock, ev)
+}
+
+func (f *EventFeed) PublishFinalizedCheckpoint(ev *eth2api.FinalizedCheckpointEvent) {
+	f.Publish(eth2api.EventTopicFinalizedCheckpoint, ev)
+}
+
+func (f *EventFeed) PublishChainReorg(ev *eth2api.ChainReorgEvent) {
+	f.Publish(eth2api.EventTopicChainReorg, ev)
+}
@@ -0,0 +1,63 @@
+package nodeapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/protolambda/eth2api"
+)
+
+// HealthStatus describes the readiness of the node, see the Health route.
+type HealthStatus uint8
+
+const (
+	// HealthReady indicates the node is synced and ready to serve requests.
+	HealthReady HealthStatus = iota
+	// HealthSyncing indicates the node is syncing, but can serve incomplete data.
+	HealthSyncing
+	// HealthNotInitialized indicates the node is not initialized or having issues.
+	HealthNotInitialized
+)
+
+// NodeBackend provides the networking, sync and version information of the node.
+//
+// Errors that implement eth2api.ApiError (e.g. *eth2api.ErrorMessage) result in a response with their status code.
+// Other errors result in a 500 response.
+type NodeBackend interface {
+	Health(ctx context.Context) (HealthStatus, error)
+	// Identity returns the network identity of the node, including the p2p metadata.
+	Identity(ctx context.Context) (*eth2api.NetworkIdentity, error)
+	// Peers returns the peers matching any of the given states and any of the given directions.
+	// An empty filter matches all peers.
+	Peers(ctx context.Context, states []eth2api.ConnectionState, directions []eth2api.ConnectionDirection) ([]eth2api.Peer, error)
+	// Peer returns nil if the peer is not known.
+	Peer(ctx context.Context, id eth2api.ApiPeerId) (*eth2api.Peer, error)
+	PeerCount(ctx context.Context) (*eth2api.PeerCountResponse, error)
+	SyncingStatus(ctx context.Context) (*eth2api.SyncingStatus, error)
+	// Version returns the implementation version, similar to a HTTP User-Agent, e.g. "Lighthouse/v0.1.5 (Linux x86_64)".
+	Version(ctx context.Context) (string, error)
+}
+
+// respondErr maps a backend error to a response.
+func respondErr(err error, msg string) eth2api.PreparedResponse {
+	var apiErr eth2api.ApiError
+	if errors.As(err, &apiErr) {
+		return eth2api.RespondApiError(apiErr)
+	}
+	return eth2api.RespondInternalError(fmt.Errorf("%s: %v", msg, err))
+}
+
+// queryList splits the values of the query param by comma, the param may also be repeated.
+func queryList(req eth2api.Request, name string) (out []string) {
+	vals, _ := req.Query(name)
+	for _, v := range vals {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
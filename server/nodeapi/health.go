@@ -0,0 +1,37 @@
+package nodeapi
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves the node health: 200 if ready, 206 if syncing, 503 if not initialized or having issues.
+// The optional 'syncing_status' query param overrides the status code used when syncing.
+func Health(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/health",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			syncingCode := uint(206)
+			if vals, ok := req.Query("syncing_status"); ok && len(vals) > 0 {
+				v, err := strconv.ParseUint(vals[0], 10, 16)
+				if err != nil || v < 100 || v > 599 {
+					return eth2api.RespondBadInput(fmt.Errorf("bad syncing_status: %q", vals[0]))
+				}
+				syncingCode = uint(v)
+			}
+			status, err := backend.Health(ctx)
+			if err != nil {
+				return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: 503, Message: err.Error()})
+			}
+			switch status {
+			case HealthReady:
+				return eth2api.RespondOKMsg("node is ready")
+			case HealthSyncing:
+				return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: syncingCode, Message: "node is syncing"})
+			default:
+				return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: 503, Message: "node is not initialized"})
+			}
+		})
+}
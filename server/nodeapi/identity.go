@@ -0,0 +1,19 @@
+package nodeapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves data about the node's network presence.
+func Identity(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/identity",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			id, err := backend.Identity(ctx)
+			if err != nil {
+				return respondErr(err, "failed to get network identity")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(id))
+		})
+}
@@ -0,0 +1,140 @@
+package nodeapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	clientapi "github.com/protolambda/eth2api/client/nodeapi"
+)
+
+type testBackend struct {
+	health HealthStatus
+	peers  []eth2api.Peer
+}
+
+func (b *testBackend) Health(ctx context.Context) (HealthStatus, error) {
+	return b.health, nil
+}
+
+func (b *testBackend) Identity(ctx context.Context) (*eth2api.NetworkIdentity, error) {
+	return &eth2api.NetworkIdentity{PeerID: "self"}, nil
+}
+
+func (b *testBackend) Peers(ctx context.Context, states []eth2api.ConnectionState, directions []eth2api.ConnectionDirection) (out []eth2api.Peer, err error) {
+	for _, p := range b.peers {
+		if (len(states) == 0 || containsState(states, p.State)) && (len(directions) == 0 || containsDirection(directions, p.Direction)) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+func containsState(states []eth2api.ConnectionState, st eth2api.ConnectionState) bool {
+	for _, s := range states {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDirection(directions []eth2api.ConnectionDirection, d eth2api.ConnectionDirection) bool {
+	for _, v := range directions {
+		if v == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *testBackend) Peer(ctx context.Context, id eth2api.ApiPeerId) (*eth2api.Peer, error) {
+	for i := range b.peers {
+		if b.peers[i].PeerID == id {
+			return &b.peers[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (b *testBackend) PeerCount(ctx context.Context) (*eth2api.PeerCountResponse, error) {
+	return &eth2api.PeerCountResponse{Connected: 2}, nil
+}
+
+func (b *testBackend) SyncingStatus(ctx context.Context) (*eth2api.SyncingStatus, error) {
+	return &eth2api.SyncingStatus{HeadSlot: 100, SyncDistance: 3}, nil
+}
+
+func (b *testBackend) Version(ctx context.Context) (string, error) {
+	return "test/v1.0.0", nil
+}
+
+func TestNodeRoutes(t *testing.T) {
+	backend := &testBackend{peers: []eth2api.Peer{
+		{PeerID: "a", State: eth2api.ConnectionStateConnected, Direction: eth2api.ConnectionDirectionInbound},
+		{PeerID: "b", State: eth2api.ConnectionStateConnected, Direction: eth2api.ConnectionDirectionOutbound},
+		{PeerID: "c", State: eth2api.ConnectionStateDisconnected, Direction: eth2api.ConnectionDirectionOutbound},
+	}}
+	router := eth2api.NewHttpRouter()
+	for _, route := range []eth2api.Route{Health(backend), Identity(backend), Peers(backend), Peer(backend),
+		PeerCount(backend), SyncingStatus(backend), NodeVersion(backend)} {
+		router.AddRoute(route)
+	}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	t.Run("health", func(t *testing.T) {
+		for _, c := range []struct {
+			status HealthStatus
+			code   uint
+		}{{HealthReady, 200}, {HealthSyncing, 206}, {HealthNotInitialized, 503}} {
+			backend.health = c.status
+			code, _ := cli.Request(ctx, eth2api.PlainGET("/eth/v1/node/health")).Decode(nil)
+			if code != c.code {
+				t.Errorf("health status %d: expected code %d, got %d", c.status, c.code, code)
+			}
+		}
+		backend.health = HealthSyncing
+		if syncing, err := clientapi.Health(ctx, cli); !syncing || err == nil {
+			t.Errorf("expected syncing, got syncing %v, err %v", syncing, err)
+		}
+	})
+
+	t.Run("peers", func(t *testing.T) {
+		var peers []eth2api.Peer
+		if err := clientapi.Peers(ctx, cli, []eth2api.ConnectionState{eth2api.ConnectionStateConnected},
+			[]eth2api.ConnectionDirection{eth2api.ConnectionDirectionOutbound}, &peers); err != nil {
+			t.Fatal(err)
+		}
+		if len(peers) != 1 || peers[0].PeerID != "b" {
+			t.Fatalf("unexpected peers: %v", peers)
+		}
+		if err := clientapi.Peers(ctx, cli, nil, nil, &peers); err != nil {
+			t.Fatal(err)
+		}
+		if len(peers) != 3 {
+			t.Fatalf("expected all peers, got %v", peers)
+		}
+		code, _ := cli.Request(ctx, eth2api.QueryGET(eth2api.Query{"state": "sleeping"}, "/eth/v1/node/peers")).Decode(nil)
+		if code != 400 {
+			t.Fatalf("expected bad input for unknown state, got %d", code)
+		}
+		var peer eth2api.Peer
+		if exists, err := clientapi.Peer(ctx, cli, "x", &peer); err != nil || exists {
+			t.Fatalf("expected unknown peer, got exists %v, err %v", exists, err)
+		}
+	})
+
+	t.Run("version", func(t *testing.T) {
+		var version eth2api.NodeVersionResponse
+		if err := clientapi.NodeVersion(ctx, cli, &version); err != nil {
+			t.Fatal(err)
+		}
+		if version.Version != "test/v1.0.0" {
+			t.Fatalf("unexpected version: %q", version.Version)
+		}
+	})
+}
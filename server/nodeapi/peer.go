@@ -0,0 +1,66 @@
+package nodeapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves data about the given peer.
+func Peer(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/peers/:peerId",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			peer, err := backend.Peer(ctx, eth2api.ApiPeerId(req.Param("peerId")))
+			if err != nil {
+				return respondErr(err, "failed to get peer")
+			}
+			if peer == nil {
+				return eth2api.RespondNotFound("Peer not found")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(peer))
+		})
+}
+
+// Serves the number of known peers.
+func PeerCount(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/peer_count",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			count, err := backend.PeerCount(ctx)
+			if err != nil {
+				return respondErr(err, "failed to get peer count")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(count))
+		})
+}
+
+// Serves data about the node's network peers, filtered by the optional 'state' and 'direction' query params.
+// Different query params are combined using AND conditions. Array items with OR conditions.
+func Peers(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/peers",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var states []eth2api.ConnectionState
+			for _, v := range queryList(req, "state") {
+				st, err := eth2api.ParseConnectionState(v)
+				if err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				states = append(states, st)
+			}
+			var directions []eth2api.ConnectionDirection
+			for _, v := range queryList(req, "direction") {
+				d, err := eth2api.ParseConnectionDirection(v)
+				if err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				directions = append(directions, d)
+			}
+			peers, err := backend.Peers(ctx, states, directions)
+			if err != nil {
+				return respondErr(err, "failed to get peers")
+			}
+			if peers == nil {
+				peers = []eth2api.Peer{}
+			}
+			return eth2api.RespondOK(eth2api.Wrap(peers))
+		})
+}
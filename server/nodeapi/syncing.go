@@ -0,0 +1,19 @@
+package nodeapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves if the node is currently syncing or not, and if it is, what block it is up to.
+func SyncingStatus(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/syncing",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			status, err := backend.SyncingStatus(ctx)
+			if err != nil {
+				return respondErr(err, "failed to get syncing status")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(status))
+		})
+}
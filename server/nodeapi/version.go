@@ -0,0 +1,19 @@
+package nodeapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Serves the implementation version of the node.
+func NodeVersion(backend NodeBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/node/version",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			version, err := backend.Version(ctx)
+			if err != nil {
+				return respondErr(err, "failed to get node version")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&eth2api.NodeVersionResponse{Version: version}))
+		})
+}
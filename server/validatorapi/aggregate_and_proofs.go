@@ -0,0 +1,23 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+// Verifies given aggregate and proofs and publishes them on appropriate gossipsub topic.
+func PublishAggregateAndProofs(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/aggregate_and_proofs",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var aggAndProofs []*phase0.SignedAggregateAndProof
+			if err := req.DecodeBody(&aggAndProofs); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if err := backend.PublishAggregateAndProofs(ctx, aggAndProofs); err != nil {
+				return respondErr(err, "failed to publish aggregate and proofs")
+			}
+			return eth2api.RespondOKMsg("published aggregate and proofs")
+		})
+}
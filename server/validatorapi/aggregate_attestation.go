@@ -0,0 +1,31 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// Serves the aggregate of all attestations matching given attestation data root and slot.
+func AggregateAttestation(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/aggregate_attestation",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			attDataRoot, err := queryRoot(req, "attestation_data_root")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			slot, err := queryUint(req, "slot")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			att, err := backend.AggregateAttestation(ctx, attDataRoot, common.Slot(slot))
+			if err != nil {
+				return respondErr(err, "failed to aggregate attestations")
+			}
+			if att == nil {
+				return eth2api.RespondNotFound("No matching attestation found")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(att))
+		})
+}
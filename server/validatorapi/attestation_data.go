@@ -0,0 +1,28 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// Produces an AttestationData for the given slot and committee.
+func AttestationData(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/attestation_data",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			slot, err := queryUint(req, "slot")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			committeeIndex, err := queryUint(req, "committee_index")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			data, err := backend.AttestationData(ctx, common.Slot(slot), common.CommitteeIndex(committeeIndex))
+			if err != nil {
+				return respondErr(err, "failed to produce attestation data")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(data))
+		})
+}
@@ -0,0 +1,89 @@
+package validatorapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+// ErrSyncing is returned by the ValidatorBackend when the node is syncing,
+// and cannot serve the validator client. The routes respond with a 503 status code.
+var ErrSyncing = errors.New("beacon node is syncing")
+
+// ValidatorBackend implements the duties and block/attestation production of the node for the validator client.
+//
+// Errors that wrap ErrSyncing result in a 503 response.
+// Errors that implement eth2api.ApiError (e.g. *eth2api.ErrorMessage) result in a response with their status code.
+// Other errors result in a 500 response.
+type ValidatorBackend interface {
+	AttesterDuties(ctx context.Context, epoch common.Epoch, indices []common.ValidatorIndex) (*eth2api.DependentAttesterDuties, error)
+	ProposerDuties(ctx context.Context, epoch common.Epoch) (*eth2api.DependentProposerDuty, error)
+	SyncCommitteeDuties(ctx context.Context, epoch common.Epoch, indices []common.ValidatorIndex) ([]eth2api.SyncCommitteeDuty, error)
+
+	// ProduceBlock produces an unsigned block of the fork of the given slot. The graffiti is optional.
+	ProduceBlock(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root) (*eth2api.VersionedBeaconBlock, error)
+	AttestationData(ctx context.Context, slot common.Slot, committeeIndex common.CommitteeIndex) (*phase0.AttestationData, error)
+	// AggregateAttestation returns nil if there is no matching attestation to aggregate.
+	AggregateAttestation(ctx context.Context, attDataRoot common.Root, slot common.Slot) (*phase0.Attestation, error)
+	PublishAggregateAndProofs(ctx context.Context, aggAndProofs []*phase0.SignedAggregateAndProof) error
+	SubscribeBeaconCommitteeSubnets(ctx context.Context, signals []*eth2api.BeaconCommitteeSubscribeSignal) error
+
+	SubscribeSyncCommitteeSubnets(ctx context.Context, signals []*eth2api.SyncCommitteeSubscribeSignal) error
+	// SyncCommitteeContribution returns nil if there is no contribution for the given block root.
+	SyncCommitteeContribution(ctx context.Context, slot common.Slot, subcommitteeIndex uint64, beaconBlockRoot common.Root) (*altair.SyncCommitteeContribution, error)
+	PublishContributionAndProofs(ctx context.Context, contribAndProofs []altair.SignedContributionAndProof) error
+}
+
+// respondErr maps a backend error to a response.
+func respondErr(err error, msg string) eth2api.PreparedResponse {
+	if errors.Is(err, ErrSyncing) {
+		return eth2api.RespondSyncing(err.Error())
+	}
+	var apiErr eth2api.ApiError
+	if errors.As(err, &apiErr) {
+		return eth2api.RespondApiError(apiErr)
+	}
+	return eth2api.RespondInternalError(fmt.Errorf("%s: %v", msg, err))
+}
+
+func parseUint(v string, name string) (uint64, error) {
+	n, err := strconv.ParseUint(v, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("bad %s: %v", name, err)
+	}
+	return n, nil
+}
+
+// requiredQuery returns the first value of the query param, or an error if it is missing.
+func requiredQuery(req eth2api.Request, name string) (string, error) {
+	vals, ok := req.Query(name)
+	if !ok || len(vals) == 0 {
+		return "", fmt.Errorf("missing %s query param", name)
+	}
+	return vals[0], nil
+}
+
+func queryUint(req eth2api.Request, name string) (uint64, error) {
+	v, err := requiredQuery(req, name)
+	if err != nil {
+		return 0, err
+	}
+	return parseUint(v, name)
+}
+
+func queryRoot(req eth2api.Request, name string) (root common.Root, err error) {
+	v, err := requiredQuery(req, name)
+	if err != nil {
+		return common.Root{}, err
+	}
+	if err := root.UnmarshalText([]byte(v)); err != nil {
+		return common.Root{}, fmt.Errorf("bad %s: %v", name, err)
+	}
+	return root, nil
+}
@@ -0,0 +1,22 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Prepares the beacon node for the committee subnets of the given subscriptions.
+func PrepareBeaconCommitteeSubnet(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/beacon_committee_subscriptions",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var signals []*eth2api.BeaconCommitteeSubscribeSignal
+			if err := req.DecodeBody(&signals); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if err := backend.SubscribeBeaconCommitteeSubnets(ctx, signals); err != nil {
+				return respondErr(err, "failed to prepare beacon committee subnets")
+			}
+			return eth2api.RespondOKMsg("prepared beacon committee subnets")
+		})
+}
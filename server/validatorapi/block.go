@@ -0,0 +1,47 @@
+package validatorapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// produceBlockParams parses the slot, randao reveal and optional graffiti of block production requests.
+func produceBlockParams(req eth2api.Request) (slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root, err error) {
+	n, err := parseUint(req.Param("slot"), "slot")
+	if err != nil {
+		return 0, common.BLSSignature{}, nil, err
+	}
+	randaoVal, err := requiredQuery(req, "randao_reveal")
+	if err != nil {
+		return 0, common.BLSSignature{}, nil, err
+	}
+	if err := randaoReveal.UnmarshalText([]byte(randaoVal)); err != nil {
+		return 0, common.BLSSignature{}, nil, fmt.Errorf("bad randao_reveal: %v", err)
+	}
+	if vals, ok := req.Query("graffiti"); ok && len(vals) > 0 {
+		graffiti = new(common.Root)
+		if err := graffiti.UnmarshalText([]byte(vals[0])); err != nil {
+			return 0, common.BLSSignature{}, nil, fmt.Errorf("bad graffiti: %v", err)
+		}
+	}
+	return common.Slot(n), randaoReveal, graffiti, nil
+}
+
+// Produces a valid versioned block, which can then be signed by a validator.
+func ProduceBlockV2(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v2/validator/blocks/:slot",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			slot, randaoReveal, graffiti, err := produceBlockParams(req)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			block, err := backend.ProduceBlock(ctx, slot, randaoReveal, graffiti)
+			if err != nil {
+				return respondErr(err, "failed to produce block")
+			}
+			return eth2api.RespondOKVersioned(block)
+		})
+}
@@ -0,0 +1,125 @@
+package validatorapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// ErrInvalidBlock is returned by the BuilderBackend when a published block was broadcast,
+// but failed validation. The routes respond with a 202 status code.
+var ErrInvalidBlock = errors.New("block was broadcast but failed validation")
+
+// BuilderBackend implements block production and publishing with blinded blocks, for the builder flow.
+//
+// Errors are mapped to responses like those of the ValidatorBackend.
+type BuilderBackend interface {
+	// ProduceBlindedBlock produces an unsigned blinded block of the fork of the given slot,
+	// with the execution payload header of a builder. The graffiti is optional.
+	ProduceBlindedBlock(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root) (*eth2api.VersionedBlindedBeaconBlock, error)
+	// ProduceBlockV3 produces an unsigned block of the fork of the given slot, with either a local payload,
+	// or the blinded payload of a builder, whichever is more valuable.
+	// The graffiti and builder boost factor (percentage multiplier of the builder payload value) are optional.
+	ProduceBlockV3(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root, builderBoostFactor *uint64) (*eth2api.ProducedBlock, error)
+	// PublishBlindedBlock unblinds the signed block with the builder, and broadcasts the full block,
+	// if it passes the broadcast validation.
+	// Errors that wrap ErrInvalidBlock indicate the block was broadcast, but failed further validation.
+	PublishBlindedBlock(ctx context.Context, block *eth2api.VersionedSignedBlindedBeaconBlock, validation eth2api.BroadcastValidation) error
+}
+
+// Produces a valid blinded block, with the execution payload header of a builder, which can then be signed by a validator.
+func ProduceBlindedBlock(backend BuilderBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/blinded_blocks/:slot",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			slot, randaoReveal, graffiti, err := produceBlockParams(req)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			block, err := backend.ProduceBlindedBlock(ctx, slot, randaoReveal, graffiti)
+			if err != nil {
+				return respondErr(err, "failed to produce blinded block")
+			}
+			return eth2api.RespondOKVersioned(block)
+		})
+}
+
+// Produces a valid block, full or blinded, which can then be signed by a validator.
+// Whether the block is blinded, and the value of the block, are also specified in the response headers.
+func ProduceBlockV3(backend BuilderBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v3/validator/blocks/:slot",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			slot, randaoReveal, graffiti, err := produceBlockParams(req)
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var builderBoostFactor *uint64
+			if vals, ok := req.Query("builder_boost_factor"); ok && len(vals) > 0 {
+				v, err := strconv.ParseUint(vals[0], 10, 64)
+				if err != nil {
+					return eth2api.RespondBadInput(fmt.Errorf("bad builder_boost_factor: %v", err))
+				}
+				builderBoostFactor = &v
+			}
+			block, err := backend.ProduceBlockV3(ctx, slot, randaoReveal, graffiti, builderBoostFactor)
+			if err != nil {
+				return respondErr(err, "failed to produce block")
+			}
+			return eth2api.RespondOKProducedBlock(block)
+		})
+}
+
+// publishBlindedBlock decodes the signed blinded block, of the fork of the required version header, and publishes it.
+func publishBlindedBlock(ctx context.Context, backend BuilderBackend, req eth2api.Request, validation eth2api.BroadcastValidation) eth2api.PreparedResponse {
+	version, ok := req.Header(eth2api.ConsensusVersionHeader)
+	if !ok {
+		return eth2api.RespondBadInput(fmt.Errorf("missing %s header", eth2api.ConsensusVersionHeader))
+	}
+	f, err := eth2api.ForkByName(version)
+	if err != nil {
+		return eth2api.RespondBadInput(err)
+	}
+	if f.SignedBlindedBeaconBlock == nil {
+		return eth2api.RespondBadInput(fmt.Errorf("no blinded blocks in fork %s", f.Name))
+	}
+	block := &eth2api.VersionedSignedBlindedBeaconBlock{Version: f.Name, Data: f.SignedBlindedBeaconBlock()}
+	if err := req.DecodeBody(block.Data); err != nil {
+		return eth2api.RespondBadInput(err)
+	}
+	if err := backend.PublishBlindedBlock(ctx, block, validation); err != nil {
+		if errors.Is(err, ErrInvalidBlock) {
+			return eth2api.RespondAccepted(err)
+		}
+		return respondErr(err, "failed to publish blinded block")
+	}
+	return eth2api.RespondOKMsg("published block")
+}
+
+// Instructs the beacon node to unblind the signed blinded block with the builder, and broadcast the full block.
+// The Eth-Consensus-Version header is required. Blocks are broadcast after gossip validation.
+func PublishBlindedBlock(backend BuilderBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/beacon/blinded_blocks",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			return publishBlindedBlock(ctx, backend, req, eth2api.BroadcastValidationGossip)
+		})
+}
+
+// Like PublishBlindedBlock, but with the broadcast validation of the optional broadcast_validation query param,
+// gossip validation by default.
+func PublishBlindedBlockV2(backend BuilderBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v2/beacon/blinded_blocks",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			validation := eth2api.BroadcastValidationGossip
+			if vals, ok := req.Query("broadcast_validation"); ok && len(vals) > 0 {
+				v, err := eth2api.ParseBroadcastValidation(vals[0])
+				if err != nil {
+					return eth2api.RespondBadInput(err)
+				}
+				validation = v
+			}
+			return publishBlindedBlock(ctx, backend, req, validation)
+		})
+}
@@ -0,0 +1,137 @@
+package validatorapi
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	beaconclient "github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/eth2api/client/multinode"
+	clientapi "github.com/protolambda/eth2api/client/validatorapi"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/view"
+)
+
+type testBuilder struct {
+	spec       *common.Spec
+	published  []*eth2api.VersionedSignedBlindedBeaconBlock
+	validation []eth2api.BroadcastValidation
+}
+
+func (b *testBuilder) blindedBlock(slot common.Slot) *eth2api.BlindedBeaconBlock {
+	body := &eth2api.DenebBlindedBeaconBlockBody{}
+	body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, b.spec.SYNC_COMMITTEE_SIZE/8)
+	return &eth2api.BlindedBeaconBlock{Slot: slot, Body: body}
+}
+
+func (b *testBuilder) ProduceBlindedBlock(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root) (*eth2api.VersionedBlindedBeaconBlock, error) {
+	return &eth2api.VersionedBlindedBeaconBlock{Version: "deneb", Data: b.blindedBlock(slot)}, nil
+}
+
+func (b *testBuilder) ProduceBlockV3(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root, builderBoostFactor *uint64) (*eth2api.ProducedBlock, error) {
+	if builderBoostFactor != nil && *builderBoostFactor == 0 {
+		return nil, ErrSyncing
+	}
+	return &eth2api.ProducedBlock{
+		Version:                 "deneb",
+		ExecutionPayloadBlinded: true,
+		ExecutionPayloadValue:   view.MustUint256("123456789012345678901234567890"),
+		ConsensusBlockValue:     view.MustUint256("42"),
+		Data:                    b.blindedBlock(slot),
+	}, nil
+}
+
+func (b *testBuilder) PublishBlindedBlock(ctx context.Context, block *eth2api.VersionedSignedBlindedBeaconBlock, validation eth2api.BroadcastValidation) error {
+	b.published = append(b.published, block)
+	b.validation = append(b.validation, validation)
+	if block.Data.Message.Slot == 13 {
+		return ErrInvalidBlock
+	}
+	return nil
+}
+
+func TestBuilderRoutes(t *testing.T) {
+	spec := configs.Minimal
+	backend := &testBuilder{spec: spec}
+	router := eth2api.NewHttpRouter()
+	router.Codecs = eth2api.Codecs{eth2api.JSONCodec{}, eth2api.SSZCodec{Spec: spec}}
+	router.AddRoute(ProduceBlindedBlock(backend))
+	router.AddRoute(ProduceBlockV3(backend))
+	router.AddRoute(PublishBlindedBlock(backend))
+	router.AddRoute(PublishBlindedBlockV2(backend))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	ctx := context.Background()
+
+	for _, codec := range []eth2api.Codec{eth2api.JSONCodec{}, eth2api.SSZCodec{Spec: spec}} {
+		cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: codec}
+
+		var blinded eth2api.VersionedBlindedBeaconBlock
+		if syncing, err := clientapi.ProduceBlindedBlock(ctx, cli, 10, common.BLSSignature{}, nil, &blinded); err != nil || syncing {
+			t.Fatalf("unexpected result: syncing %v, err %v", syncing, err)
+		}
+		if _, ok := blinded.Data.Body.(*eth2api.DenebBlindedBeaconBlockBody); !ok || blinded.Version != "deneb" || blinded.Data.Slot != 10 {
+			t.Fatalf("unexpected blinded block: %q %v", blinded.Version, blinded.Data)
+		}
+
+		var produced eth2api.ProducedBlock
+		if syncing, err := clientapi.ProduceBlockV3(ctx, cli, 11, common.BLSSignature{}, &common.Root{1}, nil, &produced); err != nil || syncing {
+			t.Fatalf("unexpected result: syncing %v, err %v", syncing, err)
+		}
+		block, ok := produced.Data.(*eth2api.BlindedBeaconBlock)
+		if !ok || !produced.ExecutionPayloadBlinded || produced.Version != "deneb" || block.Slot != 11 {
+			t.Fatalf("unexpected produced block: %v", produced)
+		}
+		if produced.ExecutionPayloadValue.String() != "123456789012345678901234567890" || produced.ConsensusBlockValue.String() != "42" {
+			t.Fatalf("unexpected block values: %s, %s", produced.ExecutionPayloadValue, produced.ConsensusBlockValue)
+		}
+		boost := uint64(0)
+		if syncing, err := clientapi.ProduceBlockV3(ctx, cli, 11, common.BLSSignature{}, nil, &boost, new(eth2api.ProducedBlock)); err == nil || !syncing {
+			t.Fatalf("expected syncing error, got syncing %v, err %v", syncing, err)
+		}
+
+		signed := &eth2api.VersionedSignedBlindedBeaconBlock{Version: "deneb",
+			Data: &eth2api.SignedBlindedBeaconBlock{Message: *backend.blindedBlock(12), Signature: common.BLSSignature{0xaa}}}
+		if valid, err := beaconclient.PublishBlindedBlock(ctx, cli, signed); err != nil || !valid {
+			t.Fatalf("unexpected result: valid %v, err %v", valid, err)
+		}
+		signed.Data.Message.Slot = 13
+		// the block is still broadcast, but the 202 status is returned as error
+		if valid, err := beaconclient.PublishBlindedBlockV2(ctx, cli, signed, eth2api.BroadcastValidationConsensus); err == nil || valid {
+			t.Fatalf("expected invalid block, got valid %v, err %v", valid, err)
+		}
+		if _, err := beaconclient.PublishBlindedBlockV2(ctx, cli, signed, "all"); err == nil {
+			t.Fatal("expected error for unknown broadcast validation")
+		}
+	}
+
+	// the SSZ headers are preset when decoding, also when the request is deferred by a multinode client
+	sszCli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.SSZCodec{Spec: spec}}
+	hedged := multinode.NewHedged([]string{"/eth/v3/validator/blocks/*"}, sszCli, sszCli)
+	var produced eth2api.ProducedBlock
+	if syncing, err := clientapi.ProduceBlockV3(ctx, hedged, 14, common.BLSSignature{}, nil, nil, &produced); err != nil || syncing {
+		t.Fatalf("unexpected result: syncing %v, err %v", syncing, err)
+	}
+	if block, ok := produced.Data.(*eth2api.BlindedBeaconBlock); !ok || !produced.ExecutionPayloadBlinded || produced.Version != "deneb" || block.Slot != 14 {
+		t.Fatalf("unexpected produced block: %v", produced)
+	}
+	if produced.ConsensusBlockValue.String() != "42" {
+		t.Fatalf("unexpected consensus block value: %s", produced.ConsensusBlockValue)
+	}
+
+	if len(backend.published) != 4 {
+		t.Fatalf("unexpected published blocks: %d", len(backend.published))
+	}
+	for i, expected := range []eth2api.BroadcastValidation{eth2api.BroadcastValidationGossip, eth2api.BroadcastValidationConsensus} {
+		if got := backend.validation[i]; got != expected {
+			t.Errorf("block %d: unexpected broadcast validation %q, expected %q", i, got, expected)
+		}
+	}
+	last := backend.published[3]
+	if last.Version != "deneb" || last.Data.Message.Slot != 13 || last.Data.Signature != (common.BLSSignature{0xaa}) {
+		t.Fatalf("unexpected published block: %v", last)
+	}
+}
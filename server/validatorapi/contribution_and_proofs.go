@@ -0,0 +1,23 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+)
+
+// Verifies and publishes the signed sync committee contribution and proofs.
+func PublishContributionAndProofs(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/contribution_and_proofs",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var contribAndProofs []altair.SignedContributionAndProof
+			if err := req.DecodeBody(&contribAndProofs); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if err := backend.PublishContributionAndProofs(ctx, contribAndProofs); err != nil {
+				return respondErr(err, "failed to publish contribution and proofs")
+			}
+			return eth2api.RespondOKMsg("published contribution and proofs")
+		})
+}
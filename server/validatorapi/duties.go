@@ -0,0 +1,64 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// Serves the attestation duties of the requested validators, for a particular epoch.
+func AttesterDuties(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/duties/attester/:epoch",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			epoch, err := parseUint(req.Param("epoch"), "epoch")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var indices []common.ValidatorIndex
+			if err := req.DecodeBody(&indices); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			duties, err := backend.AttesterDuties(ctx, common.Epoch(epoch), indices)
+			if err != nil {
+				return respondErr(err, "failed to get attester duties")
+			}
+			return eth2api.RespondOK(duties) // not wrapped, the response type already has the `data` field
+		})
+}
+
+// Serves the validators that are scheduled to propose a block in the given epoch.
+func ProposerDuties(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/duties/proposer/:epoch",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			epoch, err := parseUint(req.Param("epoch"), "epoch")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			duties, err := backend.ProposerDuties(ctx, common.Epoch(epoch))
+			if err != nil {
+				return respondErr(err, "failed to get proposer duties")
+			}
+			return eth2api.RespondOK(duties) // not wrapped, the response type already has the `data` field
+		})
+}
+
+// Serves the sync committee duties of the requested validators, for a particular epoch.
+func SyncCommitteeDuties(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/duties/sync/:epoch",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			epoch, err := parseUint(req.Param("epoch"), "epoch")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			var indices []common.ValidatorIndex
+			if err := req.DecodeBody(&indices); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			duties, err := backend.SyncCommitteeDuties(ctx, common.Epoch(epoch), indices)
+			if err != nil {
+				return respondErr(err, "failed to get sync committee duties")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(duties))
+		})
+}
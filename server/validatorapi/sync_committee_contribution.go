@@ -0,0 +1,39 @@
+package validatorapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+// Produces a sync committee contribution.
+func ProduceSyncCommitteeContribution(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.GET, "/eth/v1/validator/sync_committee_contribution",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			slot, err := queryUint(req, "slot")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			subcommitteeIndex, err := queryUint(req, "subcommittee_index")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if subcommitteeIndex >= common.SYNC_COMMITTEE_SUBNET_COUNT {
+				return eth2api.RespondBadInput(fmt.Errorf("subcommittee_index %d out of range", subcommitteeIndex))
+			}
+			beaconBlockRoot, err := queryRoot(req, "beacon_block_root")
+			if err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			contrib, err := backend.SyncCommitteeContribution(ctx, common.Slot(slot), subcommitteeIndex, beaconBlockRoot)
+			if err != nil {
+				return respondErr(err, "failed to produce sync committee contribution")
+			}
+			if contrib == nil {
+				return eth2api.RespondNotFound("No matching sync committee messages found")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(contrib))
+		})
+}
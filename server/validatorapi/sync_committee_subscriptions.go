@@ -0,0 +1,22 @@
+package validatorapi
+
+import (
+	"context"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Subscribes the beacon node to the sync committee subnets of the given subscriptions.
+func PrepareSyncCommitteeSubnet(backend ValidatorBackend) eth2api.Route {
+	return eth2api.MakeRoute(eth2api.POST, "/eth/v1/validator/sync_committee_subscriptions",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var signals []*eth2api.SyncCommitteeSubscribeSignal
+			if err := req.DecodeBody(&signals); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			if err := backend.SubscribeSyncCommitteeSubnets(ctx, signals); err != nil {
+				return respondErr(err, "failed to prepare sync committee subnets")
+			}
+			return eth2api.RespondOKMsg("prepared sync committee subnets")
+		})
+}
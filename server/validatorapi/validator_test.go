@@ -0,0 +1,188 @@
+package validatorapi
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	clientapi "github.com/protolambda/eth2api/client/validatorapi"
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+type testBackend struct {
+	syncing bool
+}
+
+func (b *testBackend) AttesterDuties(ctx context.Context, epoch common.Epoch, indices []common.ValidatorIndex) (*eth2api.DependentAttesterDuties, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	out := &eth2api.DependentAttesterDuties{DependentRoot: common.Root{1}}
+	for _, i := range indices {
+		out.Data = append(out.Data, eth2api.AttesterDuty{ValidatorIndex: i, Slot: common.Slot(epoch) * 32})
+	}
+	return out, nil
+}
+
+func (b *testBackend) ProposerDuties(ctx context.Context, epoch common.Epoch) (*eth2api.DependentProposerDuty, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (b *testBackend) SyncCommitteeDuties(ctx context.Context, epoch common.Epoch, indices []common.ValidatorIndex) ([]eth2api.SyncCommitteeDuty, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (b *testBackend) ProduceBlock(ctx context.Context, slot common.Slot, randaoReveal common.BLSSignature, graffiti *common.Root) (*eth2api.VersionedBeaconBlock, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (b *testBackend) AttestationData(ctx context.Context, slot common.Slot, committeeIndex common.CommitteeIndex) (*phase0.AttestationData, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return &phase0.AttestationData{Slot: slot, Index: committeeIndex}, nil
+}
+
+func (b *testBackend) AggregateAttestation(ctx context.Context, attDataRoot common.Root, slot common.Slot) (*phase0.Attestation, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return nil, nil
+}
+
+func (b *testBackend) PublishAggregateAndProofs(ctx context.Context, aggAndProofs []*phase0.SignedAggregateAndProof) error {
+	if b.syncing {
+		return ErrSyncing
+	}
+	return nil
+}
+
+func (b *testBackend) SubscribeBeaconCommitteeSubnets(ctx context.Context, signals []*eth2api.BeaconCommitteeSubscribeSignal) error {
+	if b.syncing {
+		return ErrSyncing
+	}
+	return nil
+}
+
+func (b *testBackend) SubscribeSyncCommitteeSubnets(ctx context.Context, signals []*eth2api.SyncCommitteeSubscribeSignal) error {
+	if b.syncing {
+		return ErrSyncing
+	}
+	return nil
+}
+
+func (b *testBackend) SyncCommitteeContribution(ctx context.Context, slot common.Slot, subcommitteeIndex uint64, beaconBlockRoot common.Root) (*altair.SyncCommitteeContribution, error) {
+	if b.syncing {
+		return nil, ErrSyncing
+	}
+	return nil, nil
+}
+
+func (b *testBackend) PublishContributionAndProofs(ctx context.Context, contribAndProofs []altair.SignedContributionAndProof) error {
+	if b.syncing {
+		return ErrSyncing
+	}
+	return nil
+}
+
+func TestValidatorRoutes(t *testing.T) {
+	backend := &testBackend{}
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(AttesterDuties(backend))
+	router.AddRoute(AttestationData(backend))
+	router.AddRoute(AggregateAttestation(backend))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	var duties eth2api.DependentAttesterDuties
+	if syncing, err := clientapi.AttesterDuties(ctx, cli, 2, []common.ValidatorIndex{3, 4}, &duties); err != nil || syncing {
+		t.Fatalf("unexpected result: syncing %v, err %v", syncing, err)
+	}
+	if len(duties.Data) != 2 || duties.Data[1].ValidatorIndex != 4 || duties.Data[1].Slot != 64 {
+		t.Fatalf("unexpected duties: %v", duties.Data)
+	}
+	backend.syncing = true
+	if syncing, err := clientapi.AttesterDuties(ctx, cli, 2, nil, &duties); err == nil || !syncing {
+		t.Fatalf("expected syncing error, got syncing %v, err %v", syncing, err)
+	}
+	backend.syncing = false
+
+	var data phase0.AttestationData
+	if err := clientapi.AttestationData(ctx, cli, 10, 3, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Slot != 10 || data.Index != 3 {
+		t.Fatalf("unexpected attestation data: %v", data)
+	}
+
+	var att phase0.Attestation
+	if err := clientapi.AggregateAttestation(ctx, cli, common.Root{}, 10, &att); err == nil {
+		t.Fatal("expected not-found error")
+	} else if apiErr, ok := err.(eth2api.ApiError); !ok || apiErr.Code() != 404 {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestValidatorRouteErrors(t *testing.T) {
+	backend := &testBackend{}
+	router := eth2api.NewHttpRouter()
+	for _, route := range []eth2api.Route{
+		AttesterDuties(backend), ProposerDuties(backend), SyncCommitteeDuties(backend),
+		ProduceBlockV2(backend), AttestationData(backend), AggregateAttestation(backend),
+		PublishAggregateAndProofs(backend), PrepareBeaconCommitteeSubnet(backend), PrepareSyncCommitteeSubnet(backend),
+		ProduceSyncCommitteeContribution(backend), PublishContributionAndProofs(backend),
+	} {
+		router.AddRoute(route)
+	}
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	cli := &eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}
+	ctx := context.Background()
+
+	root := common.Root{}.String()
+	randao := "0x" + strings.Repeat("00", 96)
+	empty := []interface{}{}
+	// valid requests, and a bad input variant of each
+	cases := []struct {
+		valid, bad eth2api.PreparedRequest
+	}{
+		{eth2api.BodyPOST("/eth/v1/validator/duties/attester/1", empty), eth2api.BodyPOST("/eth/v1/validator/duties/attester/x", empty)},
+		{eth2api.PlainGET("/eth/v1/validator/duties/proposer/1"), eth2api.PlainGET("/eth/v1/validator/duties/proposer/-1")},
+		{eth2api.BodyPOST("/eth/v1/validator/duties/sync/1", empty), eth2api.BodyPOST("/eth/v1/validator/duties/sync/1", "foo")},
+		{eth2api.PlainGET("/eth/v2/validator/blocks/1?randao_reveal=" + randao), eth2api.PlainGET("/eth/v2/validator/blocks/1?randao_reveal=0x01")},
+		{eth2api.PlainGET("/eth/v1/validator/attestation_data?slot=1&committee_index=0"), eth2api.PlainGET("/eth/v1/validator/attestation_data?slot=1")},
+		{eth2api.PlainGET("/eth/v1/validator/aggregate_attestation?slot=1&attestation_data_root=" + root), eth2api.PlainGET("/eth/v1/validator/aggregate_attestation?slot=1&attestation_data_root=0x12")},
+		{eth2api.BodyPOST("/eth/v1/validator/aggregate_and_proofs", empty), eth2api.BodyPOST("/eth/v1/validator/aggregate_and_proofs", "foo")},
+		{eth2api.BodyPOST("/eth/v1/validator/beacon_committee_subscriptions", empty), eth2api.BodyPOST("/eth/v1/validator/beacon_committee_subscriptions", "foo")},
+		{eth2api.BodyPOST("/eth/v1/validator/sync_committee_subscriptions", empty), eth2api.BodyPOST("/eth/v1/validator/sync_committee_subscriptions", "foo")},
+		{eth2api.PlainGET("/eth/v1/validator/sync_committee_contribution?slot=1&subcommittee_index=0&beacon_block_root=" + root), eth2api.PlainGET("/eth/v1/validator/sync_committee_contribution?slot=1&subcommittee_index=4&beacon_block_root=" + root)},
+		{eth2api.BodyPOST("/eth/v1/validator/contribution_and_proofs", empty), eth2api.BodyPOST("/eth/v1/validator/contribution_and_proofs", "foo")},
+	}
+	for _, c := range cases {
+		if code, err := cli.Request(ctx, c.bad).Decode(nil); code != 400 {
+			t.Errorf("%s: expected bad input, got %d: %v", c.bad.Path(), code, err)
+		}
+	}
+	backend.syncing = true
+	for _, c := range cases {
+		if code, err := cli.Request(ctx, c.valid).Decode(nil); code != 503 {
+			t.Errorf("%s: expected syncing, got %d: %v", c.valid.Path(), code, err)
+		}
+	}
+}
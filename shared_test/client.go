@@ -1,20 +1,17 @@
 package shared_test
 
 import (
-	"bytes"
 	"context"
-	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
-	"net/url"
 	"os"
 	"path"
-	"strings"
 	"testing"
 
 	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/record"
+	"github.com/protolambda/eth2api/shared_test/mocknode"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/phase0"
 )
@@ -108,77 +105,14 @@ func MustExist(exists bool, err error) error {
 	return nil
 }
 
-type mockResponse string
-
-func (mr *mockResponse) UnmarshalJSON(v []byte) error {
-	// lazy json decoding
-	*mr = mockResponse(v)
-	return nil
-}
-
+// mockExchange is a test case, the expected request and the response are served by the mock node.
 type mockExchange struct {
-	Description      string       `json:"description"`
-	Input            Input        `json:"input"`
-	ExpectedPath     string       `json:"path"`
-	ExpectedPostBody string       `json:"post_body,omitempty"`
-	Code             uint         `json:"code"`
-	Resp             mockResponse `json:"response"`
-}
-
-type requestSpy struct {
-	t *testing.T
-	*mockExchange
-}
-
-func (rs requestSpy) Decode(dest interface{}) (code uint, err error) {
-	return rs.Code, eth2api.JSONCodec{}.DecodeResponseBody(rs.Code,
-		ioutil.NopCloser(strings.NewReader(string(rs.Resp))), dest)
-}
-
-func (rs requestSpy) Request(_ context.Context, req eth2api.PreparedRequest) eth2api.Response {
-	p := "/" + req.Path()
-	if q := req.Query(); q != nil {
-		b := make(url.Values)
-		for k, v := range req.Query() {
-			if s, ok := v.(string); ok {
-				b.Set(k, s)
-			} else if sv, ok := v.(fmt.Stringer); ok {
-				b.Set(k, sv.String())
-			} else if tm, ok := v.(encoding.TextMarshaler); ok {
-				tb, err := tm.MarshalText()
-				if err != nil {
-					rs.t.Fatalf("failed to encode query key %s: %v", k, err)
-				}
-				b.Set(k, string(tb))
-			} else {
-				rs.t.Fatalf("failed to encode query key '%s': unknown type", k)
-			}
-		}
-		p += "?" + b.Encode()
-	}
-	if rs.ExpectedPath != p {
-		rs.t.Fatalf("unexpected request path: '%s', expected: '%s'", p, rs.ExpectedPath)
-	}
-	method := req.Method()
-	if rs.ExpectedPostBody != "" {
-		if method != eth2api.POST {
-			rs.t.Fatalf("expected POST type, but got enum value: %s", method)
-		}
-		var buf bytes.Buffer
-		enc := json.NewEncoder(&buf) // TODO: different content-types
-		if err := enc.Encode(req.Body()); err != nil {
-			rs.t.Fatalf("failed to encode POST body: %v", err)
-		}
-		if got := buf.String(); got != rs.ExpectedPostBody {
-			rs.t.Fatalf("unexpected POST body contents:\ngot:\n---\n%s\n---\nexpected:\n---\n%s\n---\n", got, rs.ExpectedPostBody)
-		}
-	}
-	return rs
+	Description string `json:"description"`
+	Input       Input  `json:"input"`
+	Code        uint   `json:"code"`
 }
 
 // Loads one or more test cases.
-// Each test case can be executed as an artificial API client which checks what it is being called with,
-// and then outputs the test mock response.
 func loadTests(t *testing.T, sourcePath string) []*mockExchange {
 	f, err := os.Open(sourcePath)
 	if err != nil {
@@ -193,30 +127,34 @@ func loadTests(t *testing.T, sourcePath string) []*mockExchange {
 	return mocks
 }
 
-func RunAll(t *testing.T, testsDir string, name string, caseFn func(ctx context.Context, input *Input, cli eth2api.Client) error) {
+// RunAllHTTP runs the test cases end to end:
+// each case is served by a mock beacon node over HTTP, and requested with the Eth2HttpClient.
+func RunAllHTTP(t *testing.T, testsDir string, name string, caseFn func(ctx context.Context, input *Input, cli eth2api.Client) error) {
 	t.Run(name, func(t *testing.T) {
-		cases := loadTests(t, path.Join(testsDir, name+".json"))
+		sourcePath := path.Join(testsDir, name+".json")
+		cases := loadTests(t, sourcePath)
+		exchanges, err := mocknode.LoadFiles(sourcePath)
+		if err != nil {
+			t.Fatalf("failed to load test source: %v", err)
+		}
 		for i, c := range cases {
+			ex := exchanges[i]
 			t.Run(fmt.Sprintf("case_%d", i), func(t *testing.T) {
 				t.Logf("description: %s", c.Description)
-				spy := &requestSpy{
-					t:            t,
-					mockExchange: c,
-				}
-				// TODO: test cases with timeouts?
-				ctx := context.Background()
-				err := caseFn(ctx, &c.Input, spy)
+				node := mocknode.New(t, []*record.Exchange{ex})
+				err := caseFn(context.Background(), &c.Input, node.Client())
 				if err != nil {
 					if codedErr, ok := err.(eth2api.ApiError); ok {
-						if code := codedErr.Code(); code != spy.Code {
-							t.Errorf("unexpected code change in bindings: got: %d expected: %d", code, spy.Code)
+						if code := codedErr.Code(); code != c.Code {
+							t.Errorf("unexpected code change in bindings: got: %d expected: %d", code, c.Code)
 						}
-						// error was expected if code matches.
 					} else {
-						// e.g. failed to decode response contents
 						t.Errorf("unexpected bindings error: %v", err)
 					}
 				}
+				if unserved := node.Unserved(); len(unserved) != 0 {
+					t.Errorf("expected request %s was not sent", unserved[0].Path)
+				}
 			})
 		}
 	})
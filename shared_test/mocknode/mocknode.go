@@ -0,0 +1,321 @@
+package mocknode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/record"
+)
+
+// Node is a mock beacon node, serving recorded exchanges or test vectors over HTTP,
+// to test the Eth2HttpClient and bindings end to end.
+//
+// Requests are matched by method, path, query and POST body (compared as JSON).
+// Unmatched requests are reported as test errors, with a diff against the closest expected request,
+// and answered with a 404 error.
+type Node struct {
+	T         testing.TB
+	Exchanges []*record.Exchange
+	Router    *eth2api.HttpRouter
+	Server    *httptest.Server
+
+	mu     sync.Mutex
+	served map[*record.Exchange]bool
+}
+
+// LoadFiles loads exchanges from test vector files (JSON lists) and recordings (JSONL).
+func LoadFiles(paths ...string) ([]*record.Exchange, error) {
+	var out []*record.Exchange
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open exchanges file: %w", err)
+		}
+		exchanges, err := record.ReadExchanges(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read exchanges file %s: %w", p, err)
+		}
+		out = append(out, exchanges...)
+	}
+	return out, nil
+}
+
+// New starts a mock node that serves the given exchanges, the server is closed when the test ends.
+func New(t testing.TB, exchanges []*record.Exchange) *Node {
+	n := &Node{
+		T:         t,
+		Exchanges: exchanges,
+		Router:    eth2api.NewHttpRouter(),
+		served:    make(map[*record.Exchange]bool),
+	}
+	byRoute := make(map[string][]*record.Exchange)
+	var routes []string
+	for _, ex := range exchanges {
+		method, p, _ := split(ex)
+		key := method + " " + p
+		if _, ok := byRoute[key]; !ok {
+			routes = append(routes, key)
+		}
+		byRoute[key] = append(byRoute[key], ex)
+	}
+	// the responses are replayed as recorded, in the content-type of the exchange
+	n.Router.Codec = rawCodec{contentType: "application/json"}
+	contentTypes := map[string]bool{"application/json": true}
+	n.Router.Codecs = eth2api.Codecs{n.Router.Codec}
+	for _, ex := range exchanges {
+		if ct := contentType(ex); !contentTypes[ct] {
+			contentTypes[ct] = true
+			n.Router.Codecs = append(n.Router.Codecs, rawCodec{contentType: ct})
+		}
+	}
+	// the handlers match the whole query, not just the query params that a route knows of
+	n.Router.HttpMiddleware = []eth2api.HttpMiddleware{func(method eth2api.ReqMethod, route string, next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), queryKey{}, req.URL.Query())))
+		})
+	}}
+	for _, key := range routes {
+		candidates := byRoute[key]
+		method, p, _ := split(candidates[0])
+		n.Router.AddRoute(eth2api.MakeRoute(eth2api.ReqMethod(method), p, func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			var body []byte
+			if err := req.DecodeBody(&body); err != nil {
+				return eth2api.RespondBadInput(err)
+			}
+			query, _ := ctx.Value(queryKey{}).(url.Values)
+			return n.serve(method, p, query, body, candidates)
+		}))
+	}
+	n.Router.HandleMethodNotAllowed = false
+	n.Router.NotFound = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		resp := n.serve(req.Method, req.URL.Path, req.URL.Query(), body, nil)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(int(resp.Code()))
+		_ = json.NewEncoder(w).Encode(resp.Body())
+	})
+	n.Server = httptest.NewServer(n.Router)
+	t.Cleanup(n.Server.Close)
+	return n
+}
+
+// Client returns a client for the mock node.
+func (n *Node) Client() *eth2api.Eth2HttpClient {
+	return &eth2api.Eth2HttpClient{Addr: n.Server.URL, Cli: n.Server.Client(), Codec: eth2api.JSONCodec{}}
+}
+
+// Unserved returns the exchanges that were not requested.
+func (n *Node) Unserved() (out []*record.Exchange) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, ex := range n.Exchanges {
+		if !n.served[ex] {
+			out = append(out, ex)
+		}
+	}
+	return out
+}
+
+// split returns the method, the normalized path and the query of the exchange.
+func split(ex *record.Exchange) (method string, p string, query url.Values) {
+	method = string(ex.Method)
+	if method == "" {
+		method = string(eth2api.GET)
+		if ex.PostBody != "" {
+			method = string(eth2api.POST)
+		}
+	}
+	p = ex.Path
+	if i := strings.IndexByte(p, '?'); i >= 0 {
+		query, _ = url.ParseQuery(p[i+1:])
+		p = p[:i]
+	}
+	// vectors may have been written for paths without leading slash
+	p = "/" + strings.TrimLeft(p, "/")
+	return method, p, query
+}
+
+func jsonEqual(a, b []byte) bool {
+	if len(bytes.TrimSpace(a)) == 0 || len(bytes.TrimSpace(b)) == 0 {
+		return len(bytes.TrimSpace(a)) == len(bytes.TrimSpace(b))
+	}
+	var x, y interface{}
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+	}
+	xb, _ := json.Marshal(x)
+	yb, _ := json.Marshal(y)
+	return bytes.Equal(xb, yb)
+}
+
+func requestString(method string, p string, query url.Values) string {
+	if len(query) > 0 {
+		return method + " " + p + "?" + query.Encode()
+	}
+	return method + " " + p
+}
+
+type queryKey struct{}
+
+// serve matches the request to one of the candidates, and replays its response.
+func (n *Node) serve(method string, p string, query url.Values, body []byte, candidates []*record.Exchange) eth2api.PreparedResponse {
+	got := requestString(method, p, query)
+	var bodyMismatch *record.Exchange
+	for _, ex := range candidates {
+		method, p, query := split(ex)
+		if requestString(method, p, query) != got {
+			continue
+		}
+		if method == string(eth2api.POST) && !jsonEqual(body, []byte(ex.PostBody)) {
+			bodyMismatch = ex
+			continue
+		}
+		n.mu.Lock()
+		n.served[ex] = true
+		n.mu.Unlock()
+		return replayResponse{ex: ex}
+	}
+	var msg string
+	if bodyMismatch != nil {
+		msg = fmt.Sprintf("unexpected POST body of request %s:\ngot:\n%s\nexpected:\n%s", got, body, bodyMismatch.PostBody)
+	} else {
+		msg = fmt.Sprintf("unexpected request:\n%s", n.closestDiff(got))
+	}
+	n.T.Errorf("mock node: %s", msg)
+	return eth2api.RespondApiError(&eth2api.ErrorMessage{CodeValue: 404, Message: msg})
+}
+
+// contentType returns the media type of the recorded response, JSON by default.
+func contentType(ex *record.Exchange) string {
+	for k, v := range ex.Headers {
+		if strings.EqualFold(k, "Content-Type") {
+			if mediaType, _, err := mime.ParseMediaType(v); err == nil {
+				return mediaType
+			}
+		}
+	}
+	return "application/json"
+}
+
+// rawBody is a recorded response body, only the codec of its content-type encodes it.
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
+// rawCodec writes recorded response bodies as-is, and reads request bodies as bytes.
+type rawCodec struct {
+	eth2api.JSONCodec
+	contentType string
+}
+
+func (c rawCodec) Supports(data interface{}) bool {
+	if b, ok := data.(*rawBody); ok {
+		return b.contentType == c.contentType
+	}
+	// e.g. error messages
+	return c.contentType == "application/json"
+}
+
+func (c rawCodec) EncodeResponseBody(w io.Writer, data interface{}) error {
+	if b, ok := data.(*rawBody); ok {
+		_, err := w.Write(b.data)
+		return err
+	}
+	return c.JSONCodec.EncodeResponseBody(w, data)
+}
+
+func (c rawCodec) DecodeRequestBody(r io.ReadCloser, dst interface{}) error {
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	*dst.(*[]byte) = data
+	return nil
+}
+
+func (c rawCodec) ContentType() []string {
+	return []string{c.contentType}
+}
+
+// replayResponse is the recorded response of an exchange.
+type replayResponse struct {
+	ex *record.Exchange
+}
+
+func (r replayResponse) Code() uint {
+	return r.ex.Code
+}
+
+func (r replayResponse) Body() interface{} {
+	if r.ex.RawResponse != nil {
+		return &rawBody{contentType: contentType(r.ex), data: r.ex.RawResponse}
+	}
+	return &rawBody{contentType: contentType(r.ex), data: r.ex.Response}
+}
+
+func (r replayResponse) Headers() eth2api.Headers {
+	out := make(eth2api.Headers, len(r.ex.Headers))
+	for k, v := range r.ex.Headers {
+		// the content-type is set by the codec
+		if !strings.EqualFold(k, "Content-Type") {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// closestDiff describes the difference between the request and the closest expected request.
+func (n *Node) closestDiff(got string) string {
+	best := ""
+	bestDist := -1
+	for _, ex := range n.Exchanges {
+		exp := requestString(split(ex))
+		if d := distance(got, exp); bestDist < 0 || d < bestDist {
+			best, bestDist = exp, d
+		}
+	}
+	if bestDist < 0 {
+		return fmt.Sprintf("got:      %s\nexpected: no requests", got)
+	}
+	prefix := 0
+	for prefix < len(got) && prefix < len(best) && got[prefix] == best[prefix] {
+		prefix++
+	}
+	return fmt.Sprintf("got:      %s\nexpected: %s\n          %s^", got, best, strings.Repeat(" ", prefix))
+}
+
+// distance is the Levenshtein edit distance between two strings.
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
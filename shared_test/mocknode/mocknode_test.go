@@ -0,0 +1,154 @@
+package mocknode
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/eth2api/client/record"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+)
+
+const vectors = `[
+  {
+    "description": "head header",
+    "path": "/eth/v1/beacon/headers/head",
+    "code": 200,
+    "response": {"data": {"root": "0x0100000000000000000000000000000000000000000000000000000000000000", "canonical": true,
+      "header": {"message": {"slot": "12", "proposer_index": "3",
+        "parent_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+        "state_root": "0x0000000000000000000000000000000000000000000000000000000000000000",
+        "body_root": "0x0000000000000000000000000000000000000000000000000000000000000000"},
+        "signature": "0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"}}}
+  },
+  {
+    "description": "unknown block",
+    "path": "/eth/v1/beacon/headers/0x4200000000000000000000000000000000000000000000000000000000000000",
+    "code": 404,
+    "response": {"code": 404, "message": "Block not found"}
+  }
+]
+`
+
+const recording = `{"method":"POST","path":"/eth/v1/beacon/pool/voluntary_exits","post_body":"{\"message\":{\"epoch\":\"1\",\"validator_index\":\"2\"},\"signature\":\"0x000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000\"}","code":400,"headers":{"Content-Type":"application/json"},"response":{"code":400,"message":"invalid exit"}}
+`
+
+// errorsTB captures the test errors of the mock node.
+type errorsTB struct {
+	testing.TB
+	errors []string
+}
+
+func (e *errorsTB) Errorf(format string, args ...interface{}) {
+	e.errors = append(e.errors, fmt.Sprintf(format, args...))
+}
+
+func TestMockNode(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "headers.json"), []byte(vectors), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "recording.jsonl"), []byte(recording), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	exchanges, err := LoadFiles(filepath.Join(dir, "headers.json"), filepath.Join(dir, "recording.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(exchanges) != 3 {
+		t.Fatalf("expected 3 exchanges, got %d", len(exchanges))
+	}
+	node := New(t, exchanges)
+	cli := node.Client()
+	ctx := context.Background()
+
+	var header eth2api.BeaconBlockHeaderAndInfo
+	if exists, err := beaconapi.BlockHeader(ctx, cli, eth2api.BlockHead, &header); err != nil || !exists {
+		t.Fatalf("expected head header: exists %v, err: %v", exists, err)
+	}
+	if header.Header.Message.Slot != 12 || !header.Canonical {
+		t.Fatalf("unexpected header: %v", header)
+	}
+	var root eth2api.BlockIdRoot
+	root[0] = 0x42
+	if exists, err := beaconapi.BlockHeader(ctx, cli, root, &header); err != nil || exists {
+		t.Fatalf("expected unknown block: exists %v, err: %v", exists, err)
+	}
+	exit := &phase0.SignedVoluntaryExit{Message: phase0.VoluntaryExit{Epoch: 1, ValidatorIndex: 2}}
+	err = beaconapi.SubmitVoluntaryExit(ctx, cli, exit)
+	if apiErr, ok := err.(*eth2api.ErrorMessage); !ok || apiErr.Code() != 400 || apiErr.Message != "invalid exit" {
+		t.Fatalf("expected exit error, got %v", err)
+	}
+	if unserved := node.Unserved(); len(unserved) != 0 {
+		t.Fatalf("expected all exchanges to be served, got %d unserved", len(unserved))
+	}
+}
+
+func TestMockNodeUnmatched(t *testing.T) {
+	var exchanges []*record.Exchange
+	if err := json.Unmarshal([]byte(vectors), &exchanges); err != nil {
+		t.Fatal(err)
+	}
+	tb := &errorsTB{TB: t}
+	node := New(tb, exchanges)
+	cli := node.Client()
+
+	var header eth2api.BeaconBlockHeaderAndInfo
+	// unmatched requests are answered with a 404
+	if exists, err := beaconapi.BlockHeader(context.Background(), cli, eth2api.BlockFinalized, &header); err != nil || exists {
+		t.Fatalf("expected unmatched request to not exist: exists %v, err: %v", exists, err)
+	}
+	if len(tb.errors) != 1 {
+		t.Fatalf("expected the unmatched request to be reported, got %v", tb.errors)
+	}
+	if msg := tb.errors[0]; !strings.Contains(msg, "GET /eth/v1/beacon/headers/finalized") ||
+		!strings.Contains(msg, "expected: GET /eth/v1/beacon/headers/head") {
+		t.Fatalf("expected diff against closest path, got:\n%s", msg)
+	}
+}
+
+func TestMockNodeRaw(t *testing.T) {
+	ex := &record.Exchange{
+		Path:        "/eth/v2/debug/beacon/states/head",
+		Code:        200,
+		Headers:     eth2api.Headers{"Content-Type": "application/octet-stream", eth2api.ConsensusVersionHeader: "phase0"},
+		RawResponse: []byte{1, 2, 3},
+	}
+	tb := &errorsTB{TB: t}
+	node := New(tb, []*record.Exchange{ex})
+	req, err := http.NewRequest("GET", node.Server.URL+ex.Path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "application/octet-stream")
+	resp, err := node.Server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/octet-stream" || !bytes.Equal(body, ex.RawResponse) {
+		t.Fatalf("unexpected response: %q %x", ct, body)
+	}
+	if v := resp.Header.Get(eth2api.ConsensusVersionHeader); v != "phase0" {
+		t.Fatalf("unexpected version header: %q", v)
+	}
+
+	// the query must match as a whole
+	code, err := node.Client().Request(context.Background(), eth2api.QueryGET(eth2api.Query{"foo": "bar"}, ex.Path)).Decode(nil)
+	if code != 404 || len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "GET /eth/v2/debug/beacon/states/head?foo=bar") {
+		t.Fatalf("expected unmatched query, got code %d, err %v, reported: %v", code, err, tb.errors)
+	}
+}
@@ -0,0 +1,132 @@
+package eth2api
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/ztyp/codec"
+)
+
+// SSZCodec encodes and decodes request and response bodies as SSZ.
+// The Spec is used for types with configurable lengths, like blocks and states.
+//
+// Only SSZ-typed values (common.SpecObj or codec.Serializable / codec.Deserializable) are supported.
+// Error responses are not SSZ encoded by the API, these are decoded as JSON.
+type SSZCodec struct {
+	Spec *common.Spec
+}
+
+var _ SelectiveCodec = SSZCodec{}
+
+func (c SSZCodec) Supports(data interface{}) bool {
+	switch unwrapData(data).(type) {
+	case common.SpecObj, codec.Serializable:
+		return true
+	default:
+		return false
+	}
+}
+
+// unwrapData returns the data of a DataWrap, the API wraps most JSON data with a "data" field,
+// SSZ data is never wrapped.
+func unwrapData(data interface{}) interface{} {
+	if w, ok := data.(*DataWrap); ok && w != nil {
+		return w.Data
+	}
+	return data
+}
+
+func (c SSZCodec) encode(w io.Writer, data interface{}) error {
+	data = unwrapData(data)
+	switch x := data.(type) {
+	case common.SpecObj:
+		return x.Serialize(c.Spec, codec.NewEncodingWriter(w))
+	case codec.Serializable:
+		return x.Serialize(codec.NewEncodingWriter(w))
+	default:
+		return fmt.Errorf("cannot SSZ encode type %T", data)
+	}
+}
+
+func (c SSZCodec) decode(r io.Reader, dst interface{}) error {
+	dst = unwrapData(dst)
+	var deserialize func(dr *codec.DecodingReader) error
+	switch x := dst.(type) {
+	case common.SpecObj:
+		deserialize = func(dr *codec.DecodingReader) error {
+			return x.Deserialize(c.Spec, dr)
+		}
+	case codec.Deserializable:
+		deserialize = x.Deserialize
+	default:
+		return fmt.Errorf("cannot SSZ decode into type %T", dst)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read SSZ body: %w", err)
+	}
+	return deserialize(codec.NewDecodingReader(bytes.NewReader(data), uint64(len(data))))
+}
+
+func (c SSZCodec) EncodeResponseBody(w io.Writer, data interface{}) error {
+	return c.encode(w, data)
+}
+
+func (c SSZCodec) DecodeResponseBody(code uint, r io.ReadCloser, dest interface{}) error {
+	if code != 200 {
+		// non-200 responses have no SSZ representation, and may carry a JSON error message.
+		return JSONCodec{}.DecodeResponseBody(code, r, dest)
+	}
+	defer r.Close()
+	if dest == nil {
+		return nil
+	}
+	return c.decode(r, dest)
+}
+
+func (c SSZCodec) EncodeRequestBody(w io.Writer, body interface{}) error {
+	return c.encode(w, body)
+}
+
+func (c SSZCodec) DecodeRequestBody(r io.ReadCloser, dst interface{}) error {
+	defer r.Close()
+	return c.decode(r, dst)
+}
+
+func (SSZCodec) ContentType() []string {
+	return []string{
+		"application/octet-stream",
+	}
+}
+
+// VersionedSpecObj is a versioned API type, of which the SSZ encoding does not include the version.
+type VersionedSpecObj interface {
+	ConsensusVersioned
+	common.SpecObj
+}
+
+// MarshalVersionedSSZ encodes the data of the versioned object as SSZ.
+// The fork name is returned separately, e.g. to communicate it in the Eth-Consensus-Version header.
+func MarshalVersionedSSZ(spec *common.Spec, v VersionedSpecObj) (version string, data []byte, err error) {
+	f, err := ForkByName(v.ConsensusVersion())
+	if err != nil {
+		return "", nil, err
+	}
+	var buf bytes.Buffer
+	if err := v.Serialize(spec, codec.NewEncodingWriter(&buf)); err != nil {
+		return "", nil, err
+	}
+	return f.Name, buf.Bytes(), nil
+}
+
+// UnmarshalVersionedSSZ decodes the SSZ data, of the given fork name, into the versioned object.
+func UnmarshalVersionedSSZ(spec *common.Spec, version string, data []byte, dest VersionedSpecObj) error {
+	f, err := ForkByName(version)
+	if err != nil {
+		return err
+	}
+	dest.PresetConsensusVersion(f.Name)
+	return dest.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(data), uint64(len(data))))
+}
@@ -0,0 +1,57 @@
+package eth2api
+
+import "context"
+
+// ServerEvent is an event to stream to the client, see EventSink.
+type ServerEvent struct {
+	// ID of the event, optional. Clients resume streams from the last ID they received.
+	ID string
+	// Type of the event, i.e. the event topic
+	Event string
+	// Data of the event, encoded as JSON
+	Data interface{}
+}
+
+// EventSink streams events to the client.
+type EventSink interface {
+	// Open starts the stream, if not already started by Send.
+	// The response of the handler is ignored after the stream is started.
+	Open() error
+	// Send writes the event to the client, and starts the stream if not already started.
+	// An error is returned if the event cannot be written, e.g. when the client disconnected.
+	Send(ev *ServerEvent) error
+}
+
+// StreamServer is a Server that supports streaming routes.
+type StreamServer interface {
+	Server
+	AddStreamRoute(handler StreamRoute)
+}
+
+// StreamRoute is a GET route that streams events to the client, instead of responding with a single body.
+type StreamRoute interface {
+	Route() string
+	// Handle validates the request, and then either returns a response (e.g. an error) without starting the stream,
+	// or streams events to the sink until the context is done.
+	// The context is canceled when the client disconnects.
+	Handle(ctx context.Context, req Request, sink EventSink) PreparedResponse
+}
+
+type StreamHandlerFn func(ctx context.Context, req Request, sink EventSink) PreparedResponse
+
+type streamRoute struct {
+	pattern string
+	handle  StreamHandlerFn
+}
+
+func (r *streamRoute) Route() string {
+	return r.pattern
+}
+
+func (r *streamRoute) Handle(ctx context.Context, req Request, sink EventSink) PreparedResponse {
+	return r.handle(ctx, req, sink)
+}
+
+func MakeStreamRoute(path string, handle StreamHandlerFn) StreamRoute {
+	return &streamRoute{path, handle}
+}
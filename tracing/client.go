@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/metrics"
+)
+
+// Client is an eth2api.Client decorator, e.g. of the Eth2HttpClient, that traces every request with a client span.
+// The span is propagated to the node with the traceparent header.
+//
+// The span ends when the response is decoded, or when the body of a streamed response is closed.
+// Responses with a status code of 400 or higher, responses that fail to decode,
+// and requests that fail without response, are marked as failed.
+//
+// Responses of the Eth2HttpClient are passed through as *eth2api.HttpResponse, for decorators like the cache.
+type Client struct {
+	Client eth2api.Client
+	Tracer Tracer
+	// Templates to name spans with, metrics.DefaultTemplates if nil.
+	Templates *metrics.Templates
+}
+
+var _ eth2api.Client = (*Client)(nil)
+
+func NewClient(cli eth2api.Client, tracer Tracer) *Client {
+	return &Client{Client: cli, Tracer: tracer}
+}
+
+func (c *Client) Request(ctx context.Context, req eth2api.PreparedRequest) eth2api.Response {
+	templates := c.Templates
+	if templates == nil {
+		templates = metrics.DefaultTemplates
+	}
+	route, ok := templates.Match(req.Path())
+	if !ok {
+		route = metrics.UnknownRoute
+	}
+	method := string(req.Method())
+	ctx, span := c.Tracer.Start(ctx, method+" "+route, SpanKindClient, SpanContext{})
+	span.SetAttribute(AttrMethod, method)
+	span.SetAttribute(AttrRoute, route)
+	if ok {
+		setIdKinds(span, func(param string) string { return templateParam(route, req.Path(), param) })
+	}
+	headers := eth2api.Headers{TraceparentHeader: span.SpanContext().Traceparent()}
+	if hr, ok := req.(eth2api.HeadersRequest); ok {
+		for k, v := range hr.Headers() {
+			headers[k] = v
+		}
+	}
+	e := &ending{span: span}
+	resp := c.Client.Request(ctx, eth2api.WithHeaders(req, headers))
+	if hr, ok := resp.(*eth2api.HttpResponse); ok {
+		e.setCode(uint(hr.StatusCode))
+		hr.Body = &endingBody{ReadCloser: hr.Body, e: e}
+		hr.Codec = &endingCodec{Codec: hr.Codec, e: e}
+		return hr
+	}
+	return &response{Response: resp, e: e}
+}
+
+// setIdKinds sets the kind of the state and block ids of the request, if any.
+func setIdKinds(span Span, param func(name string) string) {
+	if id := param("stateId"); id != "" {
+		span.SetAttribute(AttrStateIdKind, IdKind(id))
+	}
+	if id := param("blockId"); id != "" {
+		span.SetAttribute(AttrBlockIdKind, IdKind(id))
+	}
+}
+
+// templateParam returns the path segment of the named parameter of the route template.
+func templateParam(route string, path string, name string) string {
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	routeSegs := strings.Split(strings.Trim(route, "/"), "/")
+	pathSegs := strings.Split(strings.Trim(path, "/"), "/")
+	for i, seg := range routeSegs {
+		if seg == ":"+name && i < len(pathSegs) {
+			return pathSegs[i]
+		}
+	}
+	return ""
+}
+
+// ending ends the span once, with the status code and error of the response.
+type ending struct {
+	span Span
+	code uint
+	once sync.Once
+}
+
+func (e *ending) setCode(code uint) {
+	e.code = code
+	e.span.SetAttribute(AttrStatusCode, code)
+}
+
+func (e *ending) end(err error) {
+	e.once.Do(func() {
+		if err == nil && e.code >= 400 {
+			err = fmt.Errorf("response status code: %d", e.code)
+		}
+		if err != nil {
+			e.span.SetError(err)
+		}
+		e.span.End()
+	})
+}
+
+type endingBody struct {
+	io.ReadCloser
+	e *ending
+}
+
+func (b *endingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.e.end(nil)
+	return err
+}
+
+// endingCodec ends the span with the error of decoding the response body,
+// e.g. the *eth2api.ErrorMessage of an error response, before the body is closed.
+type endingCodec struct {
+	eth2api.Codec
+	e *ending
+}
+
+func (c *endingCodec) DecodeResponseBody(code uint, r io.ReadCloser, dest interface{}) error {
+	// the codec closes the body, the span ends after, with the error
+	if eb, ok := r.(*endingBody); ok {
+		r = eb.ReadCloser
+	}
+	err := c.Codec.DecodeResponseBody(code, r, dest)
+	c.e.end(err)
+	return err
+}
+
+type response struct {
+	eth2api.Response
+	e *ending
+}
+
+var _ eth2api.StreamResponse = (*response)(nil)
+
+func (r *response) Decode(dest interface{}) (code uint, err error) {
+	code, err = r.Response.Decode(dest)
+	if code != 0 {
+		r.e.setCode(code)
+	}
+	r.e.end(err)
+	return code, err
+}
+
+func (r *response) Stream() (code uint, body io.ReadCloser, err error) {
+	sr, ok := r.Response.(eth2api.StreamResponse)
+	if !ok {
+		code, err = r.Decode(nil)
+		if err == nil {
+			err = fmt.Errorf("client response type %T does not support streaming", r.Response)
+		}
+		return code, nil, err
+	}
+	code, body, err = sr.Stream()
+	if code != 0 {
+		r.e.setCode(code)
+	}
+	if err != nil || body == nil {
+		r.e.end(err)
+		return code, body, err
+	}
+	return code, &endingBody{ReadCloser: body, e: r.e}, nil
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+)
+
+// RecordedSpan is a span recorded by the Recorder.
+type RecordedSpan struct {
+	Name    string
+	Kind    SpanKind
+	Context SpanContext
+	// Parent is the local or remote parent span, zero if the span is a root span.
+	Parent     SpanContext
+	Attributes map[string]interface{}
+	Err        error
+	Ended      bool
+}
+
+// Recorder is a Tracer that keeps all spans in memory, e.g. for tests.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+var _ Tracer = (*Recorder)(nil)
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Spans returns copies of the spans started so far, in order of starting.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]RecordedSpan, len(r.spans))
+	for i, s := range r.spans {
+		out[i] = *s
+		out[i].Attributes = make(map[string]interface{}, len(s.Attributes))
+		for k, v := range s.Attributes {
+			out[i].Attributes[k] = v
+		}
+	}
+	return out
+}
+
+type recorderSpanKey struct{}
+
+func (r *Recorder) Start(ctx context.Context, name string, kind SpanKind, remoteParent SpanContext) (context.Context, Span) {
+	s := &RecordedSpan{Name: name, Kind: kind, Attributes: make(map[string]interface{})}
+	if parent, ok := ctx.Value(recorderSpanKey{}).(*recordedSpan); ok {
+		s.Parent = parent.s.Context
+	} else if remoteParent.IsValid() {
+		s.Parent = remoteParent
+	}
+	if s.Parent.IsValid() {
+		s.Context.TraceID = s.Parent.TraceID
+		s.Context.Flags = s.Parent.Flags
+	} else {
+		_, _ = rand.Read(s.Context.TraceID[:])
+		s.Context.Flags = 0x01
+	}
+	_, _ = rand.Read(s.Context.SpanID[:])
+	r.mu.Lock()
+	r.spans = append(r.spans, s)
+	r.mu.Unlock()
+	span := &recordedSpan{r: r, s: s}
+	return context.WithValue(ctx, recorderSpanKey{}, span), span
+}
+
+type recordedSpan struct {
+	r *Recorder
+	s *RecordedSpan
+}
+
+func (rs *recordedSpan) SpanContext() SpanContext {
+	return rs.s.Context
+}
+
+func (rs *recordedSpan) SetAttribute(key string, value interface{}) {
+	rs.r.mu.Lock()
+	defer rs.r.mu.Unlock()
+	rs.s.Attributes[key] = value
+}
+
+func (rs *recordedSpan) SetError(err error) {
+	rs.r.mu.Lock()
+	defer rs.r.mu.Unlock()
+	rs.s.Err = err
+}
+
+func (rs *recordedSpan) End() {
+	rs.r.mu.Lock()
+	defer rs.r.mu.Unlock()
+	rs.s.Ended = true
+}
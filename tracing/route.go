@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/protolambda/eth2api"
+)
+
+// Route wraps the route, to trace every request with a server span.
+// The span is a child of the client span, if the request has a valid traceparent header.
+//
+// Responses with a status code of 500 or higher are marked as failed.
+func Route(tracer Tracer, route eth2api.Route) eth2api.Route {
+	return &tracedRoute{route: route, tracer: tracer}
+}
+
+// Middleware returns a route wrapper that traces every route with the tracer, see Route.
+func Middleware(tracer Tracer) eth2api.Middleware {
+	return func(route eth2api.Route) eth2api.Route {
+		return Route(tracer, route)
+	}
+}
+
+type tracedRoute struct {
+	route  eth2api.Route
+	tracer Tracer
+}
+
+func (r *tracedRoute) Method() eth2api.ReqMethod {
+	return r.route.Method()
+}
+
+func (r *tracedRoute) Route() string {
+	return r.route.Route()
+}
+
+func (r *tracedRoute) Handle(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+	var remote SpanContext
+	if v, ok := req.Header(TraceparentHeader); ok {
+		// invalid trace context is ignored, a new trace is started instead
+		remote, _ = ParseTraceparent(v)
+	}
+	method := string(r.Method())
+	ctx, span := r.tracer.Start(ctx, method+" "+r.route.Route(), SpanKindServer, remote)
+	defer span.End()
+	span.SetAttribute(AttrMethod, method)
+	span.SetAttribute(AttrRoute, r.route.Route())
+	setIdKinds(span, req.Param)
+
+	resp := r.route.Handle(ctx, req)
+	if resp == nil {
+		return nil
+	}
+	code := resp.Code()
+	span.SetAttribute(AttrStatusCode, code)
+	if code >= 500 {
+		if err, ok := resp.Body().(error); ok {
+			span.SetError(err)
+		} else {
+			span.SetError(fmt.Errorf("response status code: %d", code))
+		}
+	}
+	return resp
+}
@@ -0,0 +1,139 @@
+// Package tracing adds spans to eth2api client requests and server routes, through a small Tracer interface,
+// to be implemented with any tracing library, e.g. OpenTelemetry.
+//
+// The trace context is propagated between clients and servers with the W3C traceparent header,
+// see https://www.w3.org/TR/trace-context/
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+type SpanKind uint8
+
+const (
+	SpanKindClient SpanKind = iota + 1
+	SpanKindServer
+)
+
+func (k SpanKind) String() string {
+	switch k {
+	case SpanKindClient:
+		return "client"
+	case SpanKindServer:
+		return "server"
+	default:
+		return fmt.Sprintf("SpanKind(%d)", uint8(k))
+	}
+}
+
+type Tracer interface {
+	// Start a span, as child of the span in the context, or else as child of the remote parent, if valid.
+	// The returned context carries the new span.
+	Start(ctx context.Context, name string, kind SpanKind, remoteParent SpanContext) (context.Context, Span)
+}
+
+type Span interface {
+	// SpanContext identifies the span, to propagate it to other services.
+	SpanContext() SpanContext
+	SetAttribute(key string, value interface{})
+	// SetError marks the span as failed.
+	SetError(err error)
+	End()
+}
+
+// Span attributes, following the OpenTelemetry semantic conventions where applicable.
+const (
+	AttrMethod      = "http.request.method"
+	AttrRoute       = "http.route"
+	AttrStatusCode  = "http.response.status_code"
+	AttrStateIdKind = "eth2api.state_id.kind"
+	AttrBlockIdKind = "eth2api.block_id.kind"
+)
+
+// Kinds of state and block ids.
+const (
+	IdKindRoot    = "root"
+	IdKindSlot    = "slot"
+	IdKindAlias   = "alias"
+	IdKindInvalid = "invalid"
+)
+
+// IdKind classifies a state or block id, e.g. "0xabc..." is a root, "123" a slot, and "head" an alias.
+func IdKind(id string) string {
+	switch id {
+	case "head", "genesis", "finalized", "justified":
+		return IdKindAlias
+	}
+	if strings.HasPrefix(id, "0x") {
+		return IdKindRoot
+	}
+	if id == "" {
+		return IdKindInvalid
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return IdKindInvalid
+		}
+	}
+	return IdKindSlot
+}
+
+// TraceparentHeader is the W3C trace context header.
+const TraceparentHeader = "Traceparent"
+
+type TraceID [16]byte
+
+type SpanID [8]byte
+
+// SpanContext identifies a span within a trace.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	// Flags of the trace, e.g. 0x01 if the trace is sampled.
+	Flags byte
+}
+
+// IsValid returns true if the trace and span ids are non-zero.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != (TraceID{}) && sc.SpanID != (SpanID{})
+}
+
+// Traceparent formats the span context as traceparent header value.
+func (sc SpanContext) Traceparent() string {
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID[:], sc.SpanID[:], sc.Flags)
+}
+
+// ParseTraceparent parses a traceparent header value.
+func ParseTraceparent(v string) (sc SpanContext, err error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("invalid traceparent: %q", v)
+	}
+	var flags [1]byte
+	if err := decodeHex(sc.TraceID[:], parts[1]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent trace id: %w", err)
+	}
+	if err := decodeHex(sc.SpanID[:], parts[2]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent parent id: %w", err)
+	}
+	if err := decodeHex(flags[:], parts[3]); err != nil {
+		return SpanContext{}, fmt.Errorf("invalid traceparent flags: %w", err)
+	}
+	sc.Flags = flags[0]
+	if !sc.IsValid() {
+		return SpanContext{}, fmt.Errorf("invalid traceparent, zero ids: %q", v)
+	}
+	return sc, nil
+}
+
+func decodeHex(dst []byte, v string) error {
+	if len(v) != len(dst)*2 || strings.ToLower(v) != v {
+		return fmt.Errorf("expected %d lower-case hex characters, got %q", len(dst)*2, v)
+	}
+	_, err := hex.Decode(dst, []byte(v))
+	return err
+}
@@ -0,0 +1,124 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/protolambda/eth2api"
+	"github.com/protolambda/eth2api/client/beaconapi"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+)
+
+func TestTraceparent(t *testing.T) {
+	sc, err := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sc.Flags != 0x01 || sc.TraceID[0] != 0x4b || sc.SpanID[7] != 0xb7 {
+		t.Fatalf("unexpected span context: %v", sc)
+	}
+	if got := sc.Traceparent(); got != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Fatalf("unexpected traceparent: %s", got)
+	}
+	for _, v := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := ParseTraceparent(v); err == nil {
+			t.Errorf("expected invalid traceparent: %q", v)
+		}
+	}
+}
+
+func TestIdKind(t *testing.T) {
+	for id, kind := range map[string]string{
+		"head": IdKindAlias, "finalized": IdKindAlias, "123": IdKindSlot,
+		"0x4242": IdKindRoot, "latest": IdKindInvalid, "-1": IdKindInvalid,
+	} {
+		if got := IdKind(id); got != kind {
+			t.Errorf("id %s: got kind %s, expected %s", id, got, kind)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	serverTracer := NewRecorder()
+	router := eth2api.NewHttpRouter()
+	router.AddRoute(Route(serverTracer, eth2api.MakeRoute(eth2api.GET, "/eth/v1/beacon/states/:stateId/root",
+		func(ctx context.Context, req eth2api.Request) eth2api.PreparedResponse {
+			switch req.Param("stateId") {
+			case "finalized":
+				return eth2api.RespondInternalError(errors.New("state unavailable"))
+			case "genesis":
+				return eth2api.RespondOK("not a root")
+			}
+			return eth2api.RespondOK(eth2api.Wrap(&eth2api.RootResponse{Root: common.Root{1}}))
+		})))
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	clientTracer := NewRecorder()
+	cli := NewClient(&eth2api.Eth2HttpClient{Addr: srv.URL, Cli: srv.Client(), Codec: eth2api.JSONCodec{}}, clientTracer)
+
+	// the client span is a child of the span in the context
+	ctx, parent := clientTracer.Start(context.Background(), "parent", SpanKindClient, SpanContext{})
+	if _, _, err := beaconapi.StateRoot(ctx, cli, eth2api.StateIdSlot(12)); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+	if _, _, err := beaconapi.StateRoot(context.Background(), cli, eth2api.StateFinalized); err == nil {
+		t.Fatal("expected error")
+	}
+	if _, _, err := beaconapi.StateRoot(context.Background(), cli, eth2api.StateGenesis); err == nil {
+		t.Fatal("expected decoding error")
+	}
+
+	const name = "GET /eth/v1/beacon/states/:stateId/root"
+	clientSpans := clientTracer.Spans()
+	serverSpans := serverTracer.Spans()
+	if len(clientSpans) != 4 || len(serverSpans) != 3 {
+		t.Fatalf("unexpected number of spans: %d client, %d server", len(clientSpans), len(serverSpans))
+	}
+	for i, s := range []RecordedSpan{clientSpans[1], clientSpans[2], serverSpans[0], serverSpans[1]} {
+		if !s.Ended || s.Name != name || s.Attributes[AttrRoute] != "/eth/v1/beacon/states/:stateId/root" || s.Attributes[AttrMethod] != "GET" {
+			t.Errorf("span %d: unexpected span: %v", i, s)
+		}
+	}
+	okClient, errClient, okServer, errServer := clientSpans[1], clientSpans[2], serverSpans[0], serverSpans[1]
+	if okClient.Parent != clientSpans[0].Context {
+		t.Errorf("expected client span to be a child of the parent span")
+	}
+	if okServer.Parent != okClient.Context || errServer.Parent != errClient.Context {
+		t.Errorf("expected server spans to be children of the client spans")
+	}
+	if okClient.Kind != SpanKindClient || okServer.Kind != SpanKindServer {
+		t.Errorf("unexpected span kinds: %s, %s", okClient.Kind, okServer.Kind)
+	}
+	if okClient.Attributes[AttrStateIdKind] != IdKindSlot || okServer.Attributes[AttrStateIdKind] != IdKindSlot {
+		t.Errorf("expected slot id kind")
+	}
+	if errClient.Attributes[AttrStateIdKind] != IdKindAlias || errServer.Attributes[AttrStateIdKind] != IdKindAlias {
+		t.Errorf("expected alias id kind")
+	}
+	if okClient.Err != nil || okServer.Err != nil || okClient.Attributes[AttrStatusCode] != uint(200) {
+		t.Errorf("unexpected OK spans: %v, %v", okClient, okServer)
+	}
+	if errClient.Err == nil || errServer.Err == nil || errServer.Attributes[AttrStatusCode] != uint(500) {
+		t.Errorf("unexpected error spans: %v, %v", errClient, errServer)
+	}
+	// the client span records the error message of the node
+	var msg *eth2api.ErrorMessage
+	if !errors.As(errClient.Err, &msg) || msg.CodeValue != 500 || !strings.Contains(msg.Message, "state unavailable") {
+		t.Errorf("expected error message of the node, got: %v", errClient.Err)
+	}
+	if decodeClient := clientSpans[3]; decodeClient.Err == nil || decodeClient.Attributes[AttrStatusCode] != uint(200) {
+		t.Errorf("expected decoding error to be recorded, got: %v", decodeClient)
+	}
+}
@@ -7,12 +7,10 @@ import (
 	"strings"
 
 	"github.com/protolambda/zrnt/eth2/beacon"
-	"github.com/protolambda/zrnt/eth2/beacon/altair"
-	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
-	"github.com/protolambda/zrnt/eth2/beacon/capella"
 	"github.com/protolambda/zrnt/eth2/beacon/common"
 	"github.com/protolambda/zrnt/eth2/beacon/phase0"
 	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
 	"github.com/protolambda/ztyp/view"
 )
 
@@ -165,6 +163,25 @@ const (
 	ValidatorStatusWithdrawn                   ValidatorStatus = "withdrawn"
 )
 
+// status names as standardized in the API spec
+const (
+	ValidatorStatusPendingInitialized ValidatorStatus = "pending_initialized"
+	ValidatorStatusPendingQueued      ValidatorStatus = "pending_queued"
+	ValidatorStatusActiveOngoing      ValidatorStatus = "active_ongoing"
+	ValidatorStatusActiveExiting      ValidatorStatus = "active_exiting"
+	ValidatorStatusActiveSlashed      ValidatorStatus = "active_slashed"
+	ValidatorStatusExitedUnslashed    ValidatorStatus = "exited_unslashed"
+	// ValidatorStatusExitedSlashed is shared with the lighthouse names
+	ValidatorStatusWithdrawalPossible ValidatorStatus = "withdrawal_possible"
+	ValidatorStatusWithdrawalDone     ValidatorStatus = "withdrawal_done"
+)
+
+// Matches checks if the status matches the status filter value,
+// which may be a full status name, or a generic status like "active", "pending", "exited" or "withdrawal".
+func (vs ValidatorStatus) Matches(filter ValidatorStatus) bool {
+	return vs == filter || strings.HasPrefix(string(vs), string(filter)+"_")
+}
+
 type Committee struct {
 	// Committee index at a slot
 	Index common.CommitteeIndex `json:"index"`
@@ -187,43 +204,134 @@ type versionStruct struct {
 	Version string `json:"version"`
 }
 
+// ConsensusVersioned is implemented by the API types that are versioned by fork name.
+// The version can be preset, for decoding data that does not specify the version itself,
+// e.g. when the version is communicated in the Eth-Consensus-Version header.
+type ConsensusVersioned interface {
+	// ConsensusVersion returns the fork name of the versioned object
+	ConsensusVersion() string
+	// PresetConsensusVersion sets the version to decode with,
+	// if the encoded object does not specify a version.
+	PresetConsensusVersion(version string)
+}
+
+// decodeVersion returns the version specified in the JSON object, or the preset version if there is none.
+func decodeVersion(b []byte, preset string) (string, error) {
+	var version versionStruct
+	if err := json.Unmarshal(b, &version); err != nil {
+		return "", err
+	}
+	if version.Version == "" {
+		return preset, nil
+	}
+	return version.Version, nil
+}
+
+type versionedStruct struct {
+	Version string      `json:"version"`
+	Data    interface{} `json:"data"`
+}
+
+// encodeVersioned encodes the data as versioned JSON object, with the fork name of the version.
+func encodeVersioned(version string, data interface{}) ([]byte, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("no data (version: %q)", version)
+	}
+	return json.Marshal(&versionedStruct{Version: f.Name, Data: data})
+}
+
 type blockDataStruct struct {
 	Data common.SpecObj `json:"data"`
 }
 
+func newBeaconBlock(version string) (common.SpecObj, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	return f.BlockContents(), nil
+}
+
+// VersionedBeaconBlock is a produced block, for block proposals.
 type VersionedBeaconBlock struct {
 	Version string `json:"version"`
-	// Data is *phase0.BeaconBlock, *altair.BeaconBlock,
-	// *bellatrix.BeaconBlock or *sharding.BeaconBlock.
+	// Data is *phase0.BeaconBlock, *altair.BeaconBlock, *bellatrix.BeaconBlock or *capella.BeaconBlock,
+	// or *BlockContents since deneb, see Fork.BlockContents.
 	Data common.SpecObj `json:"data"`
 }
 
+func (v *VersionedBeaconBlock) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedBeaconBlock) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+func (v *VersionedBeaconBlock) MarshalJSON() ([]byte, error) {
+	return encodeVersioned(v.Version, v.Data)
+}
+
 func (v *VersionedBeaconBlock) UnmarshalJSON(b []byte) error {
-	var version versionStruct
-	if err := json.Unmarshal(b, &version); err != nil {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
 		return err
 	}
 	var data blockDataStruct
-	switch strings.ToLower(v.Version) {
-	case "phase0":
-		data.Data = new(phase0.BeaconBlock)
-	case "altair":
-		data.Data = new(altair.BeaconBlock)
-	case "bellatrix":
-		data.Data = new(bellatrix.BeaconBlock)
-	case "capella":
-		data.Data = new(capella.BeaconBlock)
-	default:
-		return fmt.Errorf("unrecognized version: %q", version.Version)
+	data.Data, err = newBeaconBlock(version)
+	if err != nil {
+		return err
 	}
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
 	v.Data = data.Data
-	v.Version = version.Version
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded block, of the preset version.
+func (v *VersionedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newBeaconBlock(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
 	return nil
 }
 
+func (v *VersionedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedBeaconBlock) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedBeaconBlock) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
 type SignedBeaconBlock interface {
 	beacon.OpaqueBlock
 }
@@ -232,54 +340,573 @@ type signedBlockDataStruct struct {
 	Data SignedBeaconBlock `json:"data"`
 }
 
+func newSignedBeaconBlock(version string) (SignedBeaconBlock, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	return f.SignedBeaconBlock(), nil
+}
+
 type VersionedSignedBeaconBlock struct {
 	Version string `json:"version"`
-	// Data is *phase0.SignedBeaconBlock, *altair.SignedBeaconBlock,
-	// *bellatrix.SignedBeaconBlock or *sharding.SignedBeaconBlock.
+	// Data is *phase0.SignedBeaconBlock, *altair.SignedBeaconBlock, *bellatrix.SignedBeaconBlock,
+	// *capella.SignedBeaconBlock, *deneb.SignedBeaconBlock or *electra.SignedBeaconBlock.
 	Data SignedBeaconBlock `json:"data"`
 }
 
+func (v *VersionedSignedBeaconBlock) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedSignedBeaconBlock) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+func (v *VersionedSignedBeaconBlock) MarshalJSON() ([]byte, error) {
+	return encodeVersioned(v.Version, v.Data)
+}
+
 func (v *VersionedSignedBeaconBlock) UnmarshalJSON(b []byte) error {
-	var version versionStruct
-	if err := json.Unmarshal(b, &version); err != nil {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
 		return err
 	}
 	var data signedBlockDataStruct
-	switch strings.ToLower(version.Version) {
-	case "phase0":
-		data.Data = new(phase0.SignedBeaconBlock)
-	case "altair":
-		data.Data = new(altair.SignedBeaconBlock)
-	case "bellatrix":
-		data.Data = new(bellatrix.SignedBeaconBlock)
-	case "capella":
-		data.Data = new(capella.SignedBeaconBlock)
-	default:
-		return fmt.Errorf("unrecognized version: %q", version.Version)
+	data.Data, err = newSignedBeaconBlock(version)
+	if err != nil {
+		return err
 	}
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
 	v.Data = data.Data
-	v.Version = version.Version
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded signed block, of the preset version.
+func (v *VersionedSignedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newSignedBeaconBlock(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
 	return nil
 }
 
+func (v *VersionedSignedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedSignedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedSignedBeaconBlock) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedSignedBeaconBlock) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
+func newSignedBlockContents(version string) (common.SpecObj, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	return f.SignedBlockContents(), nil
+}
+
+// VersionedSignedBlockContents is a signed block to publish, with the blobs of the block since deneb.
+type VersionedSignedBlockContents struct {
+	Version string `json:"version"`
+	// Data is the signed block before deneb, or *SignedBlockContents since deneb, see Fork.SignedBlockContents.
+	Data common.SpecObj `json:"data"`
+}
+
+func (v *VersionedSignedBlockContents) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedSignedBlockContents) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+func (v *VersionedSignedBlockContents) MarshalJSON() ([]byte, error) {
+	return encodeVersioned(v.Version, v.Data)
+}
+
+func (v *VersionedSignedBlockContents) UnmarshalJSON(b []byte) error {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
+		return err
+	}
+	var data blockDataStruct
+	data.Data, err = newSignedBlockContents(version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	v.Data = data.Data
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded signed block contents, of the preset version.
+func (v *VersionedSignedBlockContents) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newSignedBlockContents(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
+	return nil
+}
+
+func (v *VersionedSignedBlockContents) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedSignedBlockContents) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedSignedBlockContents) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedSignedBlockContents) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
+func newBlindedBeaconBlock(version string) (*BlindedBeaconBlock, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	if f.BlindedBeaconBlock == nil {
+		return nil, fmt.Errorf("no blinded blocks in fork %s", f.Name)
+	}
+	return f.BlindedBeaconBlock(), nil
+}
+
+type blindedBlockDataStruct struct {
+	Data *BlindedBeaconBlock `json:"data"`
+}
+
+// VersionedBlindedBeaconBlock is a produced blinded block, for block proposals with the builder flow.
+type VersionedBlindedBeaconBlock struct {
+	Version string `json:"version"`
+	// Data has the blinded body of the fork, see Fork.BlindedBeaconBlockBody.
+	Data *BlindedBeaconBlock `json:"data"`
+}
+
+func (v *VersionedBlindedBeaconBlock) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedBlindedBeaconBlock) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+func (v *VersionedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
+	if v.Data == nil {
+		return nil, fmt.Errorf("no blinded block (version: %q)", v.Version)
+	}
+	return encodeVersioned(v.Version, v.Data)
+}
+
+func (v *VersionedBlindedBeaconBlock) UnmarshalJSON(b []byte) error {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
+		return err
+	}
+	var data blindedBlockDataStruct
+	data.Data, err = newBlindedBeaconBlock(version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	v.Data = data.Data
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded blinded block, of the preset version.
+func (v *VersionedBlindedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newBlindedBeaconBlock(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
+	return nil
+}
+
+func (v *VersionedBlindedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no blinded block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedBlindedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedBlindedBeaconBlock) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedBlindedBeaconBlock) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
+func newSignedBlindedBeaconBlock(version string) (*SignedBlindedBeaconBlock, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	if f.SignedBlindedBeaconBlock == nil {
+		return nil, fmt.Errorf("no blinded blocks in fork %s", f.Name)
+	}
+	return f.SignedBlindedBeaconBlock(), nil
+}
+
+type signedBlindedBlockDataStruct struct {
+	Data *SignedBlindedBeaconBlock `json:"data"`
+}
+
+// VersionedSignedBlindedBeaconBlock is a signed blinded block, to publish with the builder flow.
+type VersionedSignedBlindedBeaconBlock struct {
+	Version string `json:"version"`
+	// Data has the blinded body of the fork, see Fork.BlindedBeaconBlockBody.
+	Data *SignedBlindedBeaconBlock `json:"data"`
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) MarshalJSON() ([]byte, error) {
+	if v.Data == nil {
+		return nil, fmt.Errorf("no signed blinded block (version: %q)", v.Version)
+	}
+	return encodeVersioned(v.Version, v.Data)
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) UnmarshalJSON(b []byte) error {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
+		return err
+	}
+	var data signedBlindedBlockDataStruct
+	data.Data, err = newSignedBlindedBeaconBlock(version)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	v.Data = data.Data
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded signed blinded block, of the preset version.
+func (v *VersionedSignedBlindedBeaconBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newSignedBlindedBeaconBlock(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
+	return nil
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no signed blinded block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedSignedBlindedBeaconBlock) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
+func newProducedBlock(version string, blinded bool) (common.SpecObj, error) {
+	if blinded {
+		return newBlindedBeaconBlock(version)
+	}
+	return newBeaconBlock(version)
+}
+
+type producedBlockStruct struct {
+	Version                 string           `json:"version"`
+	ExecutionPayloadBlinded bool             `json:"execution_payload_blinded"`
+	ExecutionPayloadValue   view.Uint256View `json:"execution_payload_value"`
+	ConsensusBlockValue     view.Uint256View `json:"consensus_block_value"`
+	Data                    common.SpecObj   `json:"data"`
+}
+
+type producedBlockHeaderStruct struct {
+	Version                 string           `json:"version"`
+	ExecutionPayloadBlinded *bool            `json:"execution_payload_blinded"`
+	ExecutionPayloadValue   view.Uint256View `json:"execution_payload_value"`
+	ConsensusBlockValue     view.Uint256View `json:"consensus_block_value"`
+}
+
+// ProducedBlock is a block produced with produce block v3:
+// the block contents of the fork (see Fork.BlockContents), or a blinded block if the payload is built by a builder.
+//
+// Only the data is SSZ encoded. The version, whether the block is blinded, and the values are communicated
+// in the response headers, and are preset with PresetHeaders when decoding responses, see HeadersPreset.
+type ProducedBlock struct {
+	Version                 string
+	ExecutionPayloadBlinded bool
+	// Value of the execution payload, in wei.
+	ExecutionPayloadValue view.Uint256View
+	// Value of the consensus rewards of the block, in gwei.
+	ConsensusBlockValue view.Uint256View
+	// Data is the block contents of the fork, or *BlindedBeaconBlock if ExecutionPayloadBlinded.
+	Data common.SpecObj
+}
+
+func (v *ProducedBlock) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *ProducedBlock) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
+// PresetHeaders presets the version, whether the block is blinded, and the values,
+// to those specified by the response headers, if any.
+func (v *ProducedBlock) PresetHeaders(headers Headers) {
+	if version, ok := headers[ConsensusVersionHeader]; ok && version != "" {
+		v.Version = version
+	}
+	if blinded, ok := headers[ExecutionPayloadBlindedHeader]; ok {
+		v.ExecutionPayloadBlinded = blinded == "true"
+	}
+	if value, ok := headers[ExecutionPayloadValueHeader]; ok {
+		_ = v.ExecutionPayloadValue.UnmarshalText([]byte(value))
+	}
+	if value, ok := headers[ConsensusBlockValueHeader]; ok {
+		_ = v.ConsensusBlockValue.UnmarshalText([]byte(value))
+	}
+}
+
+// Headers returns the response headers of the produced block, the inverse of PresetHeaders.
+func (v *ProducedBlock) Headers() Headers {
+	blinded := "false"
+	if v.ExecutionPayloadBlinded {
+		blinded = "true"
+	}
+	return Headers{
+		ConsensusVersionHeader:        v.Version,
+		ExecutionPayloadBlindedHeader: blinded,
+		ExecutionPayloadValueHeader:   v.ExecutionPayloadValue.String(),
+		ConsensusBlockValueHeader:     v.ConsensusBlockValue.String(),
+	}
+}
+
+func (v *ProducedBlock) MarshalJSON() ([]byte, error) {
+	f, err := ForkByName(v.Version)
+	if err != nil {
+		return nil, err
+	}
+	if v.Data == nil {
+		return nil, fmt.Errorf("no block (version: %q)", v.Version)
+	}
+	return json.Marshal(&producedBlockStruct{
+		Version:                 f.Name,
+		ExecutionPayloadBlinded: v.ExecutionPayloadBlinded,
+		ExecutionPayloadValue:   v.ExecutionPayloadValue,
+		ConsensusBlockValue:     v.ConsensusBlockValue,
+		Data:                    v.Data,
+	})
+}
+
+func (v *ProducedBlock) UnmarshalJSON(b []byte) error {
+	var header producedBlockHeaderStruct
+	if err := json.Unmarshal(b, &header); err != nil {
+		return err
+	}
+	version := header.Version
+	if version == "" {
+		version = v.Version
+	}
+	blinded := v.ExecutionPayloadBlinded
+	if header.ExecutionPayloadBlinded != nil {
+		blinded = *header.ExecutionPayloadBlinded
+	}
+	var data blockDataStruct
+	var err error
+	data.Data, err = newProducedBlock(version, blinded)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	v.Version = version
+	v.ExecutionPayloadBlinded = blinded
+	v.ExecutionPayloadValue = header.ExecutionPayloadValue
+	v.ConsensusBlockValue = header.ConsensusBlockValue
+	v.Data = data.Data
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded block, of the preset version, blinded if preset as blinded.
+func (v *ProducedBlock) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newProducedBlock(v.Version, v.ExecutionPayloadBlinded)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
+	return nil
+}
+
+func (v *ProducedBlock) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no block (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *ProducedBlock) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *ProducedBlock) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *ProducedBlock) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
 type stateDataStruct struct {
 	Data common.SpecObj `json:"data"`
 }
 
+// SignedBeaconBlockVersion returns the fork name of the signed block type, as used to tag versioned objects.
+func SignedBeaconBlockVersion(block common.SpecObj) (string, error) {
+	f, err := ForkBySignedBeaconBlock(block)
+	if err != nil {
+		return "", err
+	}
+	return f.Name, nil
+}
+
+// BeaconStateVersion returns the fork name of the binary-tree backed state type, as used to tag versioned objects.
+func BeaconStateVersion(state common.BeaconState) (string, error) {
+	f, err := ForkByBeaconState(state)
+	if err != nil {
+		return "", err
+	}
+	return f.Name, nil
+}
+
+func newBeaconState(version string) (common.SpecObj, error) {
+	f, err := ForkByName(version)
+	if err != nil {
+		return nil, err
+	}
+	return f.BeaconState(), nil
+}
+
 type VersionedBeaconState struct {
 	Version string `json:"version"`
-	// Data is *phase0.BeaconState, *altair.BeaconState, *bellatrix.BeaconState or *sharding.BeaconState.
+	// Data is the state of the fork, e.g. *phase0.BeaconState or *electra.BeaconState, see Fork.BeaconState.
 	// See the Tree(spec) method to transform into a binary-tree backed state for advanced processing.
 	Data common.SpecObj `json:"data"`
 }
 
+func (v *VersionedBeaconState) ConsensusVersion() string {
+	return v.Version
+}
+
+func (v *VersionedBeaconState) PresetConsensusVersion(version string) {
+	v.Version = version
+}
+
 func (v *VersionedBeaconState) Tree(spec *common.Spec) (common.BeaconState, error) {
 	if v.Data == nil {
 		return nil, fmt.Errorf("no state (version: %q)", v.Version)
 	}
+	f, err := ForkByName(v.Version)
+	if err != nil {
+		return nil, err
+	}
 	var buf bytes.Buffer
 	w := codec.NewEncodingWriter(&buf)
 	if err := v.Data.Serialize(spec, w); err != nil {
@@ -287,46 +914,99 @@ func (v *VersionedBeaconState) Tree(spec *common.Spec) (common.BeaconState, erro
 	}
 	data := buf.Bytes()
 	r := codec.NewDecodingReader(bytes.NewReader(data), uint64(len(data)))
-	switch strings.ToLower(v.Version) {
-	case "phase0":
-		return phase0.AsBeaconStateView(phase0.BeaconStateType(spec).Deserialize(r))
-	case "altair":
-		return altair.AsBeaconStateView(altair.BeaconStateType(spec).Deserialize(r))
-	case "bellatrix":
-		return bellatrix.AsBeaconStateView(bellatrix.BeaconStateType(spec).Deserialize(r))
-	case "capella":
-		return capella.AsBeaconStateView(capella.BeaconStateType(spec).Deserialize(r))
-	default:
-		return nil, fmt.Errorf("unrecognized version: %q", v.Version)
+	return f.BeaconStateView(spec, r)
+}
+
+// FromTree sets the versioned state to the given binary-tree backed state, the inverse of Tree(spec).
+// The version is derived from the type of the state.
+func (v *VersionedBeaconState) FromTree(spec *common.Spec, state common.BeaconState) error {
+	version, err := BeaconStateVersion(state)
+	if err != nil {
+		return err
+	}
+	ser, ok := state.(interface {
+		Serialize(w *codec.EncodingWriter) error
+	})
+	if !ok {
+		return fmt.Errorf("cannot serialize state type %T", state)
+	}
+	var buf bytes.Buffer
+	if err := ser.Serialize(codec.NewEncodingWriter(&buf)); err != nil {
+		return err
 	}
+	data, err := newBeaconState(version)
+	if err != nil {
+		return err
+	}
+	b := buf.Bytes()
+	if err := data.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(b), uint64(len(b)))); err != nil {
+		return err
+	}
+	v.Version = version
+	v.Data = data
+	return nil
+}
+
+func (v *VersionedBeaconState) MarshalJSON() ([]byte, error) {
+	return encodeVersioned(v.Version, v.Data)
 }
 
 func (v *VersionedBeaconState) UnmarshalJSON(b []byte) error {
-	var version versionStruct
-	if err := json.Unmarshal(b, &version); err != nil {
+	version, err := decodeVersion(b, v.Version)
+	if err != nil {
 		return err
 	}
 	var data stateDataStruct
-	switch strings.ToLower(version.Version) {
-	case "phase0":
-		data.Data = new(phase0.BeaconState)
-	case "altair":
-		data.Data = new(altair.BeaconState)
-	case "bellatrix":
-		data.Data = new(bellatrix.BeaconState)
-	case "capella":
-		data.Data = new(capella.BeaconState)
-	default:
-		return fmt.Errorf("unrecognized version: %q", version.Version)
+	data.Data, err = newBeaconState(version)
+	if err != nil {
+		return err
 	}
 	if err := json.Unmarshal(b, &data); err != nil {
 		return err
 	}
 	v.Data = data.Data
-	v.Version = version.Version
+	v.Version = version
+	return nil
+}
+
+// Deserialize decodes the SSZ encoded state, of the preset version.
+func (v *VersionedBeaconState) Deserialize(spec *common.Spec, dr *codec.DecodingReader) error {
+	data, err := newBeaconState(v.Version)
+	if err != nil {
+		return err
+	}
+	if err := data.Deserialize(spec, dr); err != nil {
+		return err
+	}
+	v.Data = data
 	return nil
 }
 
+func (v *VersionedBeaconState) Serialize(spec *common.Spec, w *codec.EncodingWriter) error {
+	if v.Data == nil {
+		return fmt.Errorf("no state (version: %q)", v.Version)
+	}
+	return v.Data.Serialize(spec, w)
+}
+
+func (v *VersionedBeaconState) ByteLength(spec *common.Spec) uint64 {
+	if v.Data == nil {
+		return 0
+	}
+	return v.Data.ByteLength(spec)
+}
+
+func (v *VersionedBeaconState) HashTreeRoot(spec *common.Spec, h tree.HashFn) common.Root {
+	if v.Data == nil {
+		return common.Root{}
+	}
+	return v.Data.HashTreeRoot(spec, h)
+}
+
+func (v *VersionedBeaconState) FixedLength(spec *common.Spec) uint64 {
+	return 0
+}
+
 // Network identity data, not typed in detail,
 // using libp2p and discv5 dependencies for further processing is optional.
 type NetworkIdentity struct {
@@ -368,6 +1048,15 @@ const (
 	ConnectionDirectionOutbound ConnectionDirection = "outbound"
 )
 
+func ParseConnectionDirection(v string) (ConnectionDirection, error) {
+	switch d := ConnectionDirection(v); d {
+	case ConnectionDirectionInbound, ConnectionDirectionOutbound:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown connection direction: %q", v)
+	}
+}
+
 type ConnectionState string
 
 const (
@@ -377,6 +1066,15 @@ const (
 	ConnectionStateDisconnecting ConnectionState = "disconnecting"
 )
 
+func ParseConnectionState(v string) (ConnectionState, error) {
+	switch st := ConnectionState(v); st {
+	case ConnectionStateDisconnected, ConnectionStateConnecting, ConnectionStateConnected, ConnectionStateDisconnecting:
+		return st, nil
+	default:
+		return "", fmt.Errorf("unknown connection state: %q", v)
+	}
+}
+
 // Cryptographic hash of a peer’s public key. [Read more](https://docs.libp2p.io/concepts/peer-id/)
 // Further (optional) processing in Go with: https://github.com/libp2p/go-libp2p-core/blob/a39b84ea2e340466d57fdb342c7d62f12957d972/peer/peer.go#L42
 type ApiPeerId string
@@ -425,3 +1123,94 @@ func (m *ErrorMessage) IndexedErrors() []IndexedErrorMessageItem {
 type IndexedError interface {
 	IndexedErrors() []IndexedErrorMessageItem
 }
+
+// Topic of events, as served on the event stream.
+type EventTopic string
+
+const (
+	EventTopicHead                 EventTopic = "head"
+	EventTopicBlock                EventTopic = "block"
+	EventTopicAttestation          EventTopic = "attestation"
+	EventTopicVoluntaryExit        EventTopic = "voluntary_exit"
+	EventTopicFinalizedCheckpoint  EventTopic = "finalized_checkpoint"
+	EventTopicChainReorg           EventTopic = "chain_reorg"
+	EventTopicContributionAndProof EventTopic = "contribution_and_proof"
+	EventTopicPayloadAttributes    EventTopic = "payload_attributes"
+	EventTopicBlobSidecar          EventTopic = "blob_sidecar"
+)
+
+// Event of the "head" topic: the node has finished processing, resulting in a new head.
+type HeadEvent struct {
+	Slot  common.Slot `json:"slot"`
+	Block common.Root `json:"block"`
+	State common.Root `json:"state"`
+	// Whether the new head is the first block of a new epoch
+	EpochTransition bool `json:"epoch_transition"`
+	// Dependent root of the duties of the previous epoch of the new head
+	PreviousDutyDependentRoot common.Root `json:"previous_duty_dependent_root"`
+	// Dependent root of the duties of the current epoch of the new head
+	CurrentDutyDependentRoot common.Root `json:"current_duty_dependent_root"`
+	ExecutionOptimistic      bool        `json:"execution_optimistic"`
+}
+
+// Event of the "block" topic: the node has received a valid block, from P2P or the API.
+type BlockEvent struct {
+	Slot                common.Slot `json:"slot"`
+	Block               common.Root `json:"block"`
+	ExecutionOptimistic bool        `json:"execution_optimistic"`
+}
+
+// Event of the "finalized_checkpoint" topic: the finalized checkpoint has been updated.
+type FinalizedCheckpointEvent struct {
+	Block               common.Root  `json:"block"`
+	State               common.Root  `json:"state"`
+	Epoch               common.Epoch `json:"epoch"`
+	ExecutionOptimistic bool         `json:"execution_optimistic"`
+}
+
+// Event of the "chain_reorg" topic: the node has reorganized its chain.
+type ChainReorgEvent struct {
+	Slot                common.Slot     `json:"slot"`
+	Depth               view.Uint64View `json:"depth"`
+	OldHeadBlock        common.Root     `json:"old_head_block"`
+	NewHeadBlock        common.Root     `json:"new_head_block"`
+	OldHeadState        common.Root     `json:"old_head_state"`
+	NewHeadState        common.Root     `json:"new_head_state"`
+	Epoch               common.Epoch    `json:"epoch"`
+	ExecutionOptimistic bool            `json:"execution_optimistic"`
+}
+
+// Payload attributes, as sent to the execution engine to build a payload with.
+type PayloadAttributes struct {
+	Timestamp             common.Timestamp   `json:"timestamp"`
+	PrevRandao            common.Bytes32     `json:"prev_randao"`
+	SuggestedFeeRecipient common.Eth1Address `json:"suggested_fee_recipient"`
+	// Withdrawals, capella and later
+	Withdrawals *common.Withdrawals `json:"withdrawals,omitempty"`
+	// Parent beacon block root, deneb and later
+	ParentBeaconBlockRoot *common.Root `json:"parent_beacon_block_root,omitempty"`
+}
+
+type PayloadAttributesEventData struct {
+	ProposerIndex     common.ValidatorIndex `json:"proposer_index"`
+	ProposalSlot      common.Slot           `json:"proposal_slot"`
+	ParentBlockNumber view.Uint64View       `json:"parent_block_number"`
+	ParentBlockRoot   common.Root           `json:"parent_block_root"`
+	ParentBlockHash   common.Hash32         `json:"parent_block_hash"`
+	PayloadAttributes PayloadAttributes     `json:"payload_attributes"`
+}
+
+// Event of the "payload_attributes" topic: the node has computed new payload attributes for execution payload building.
+type PayloadAttributesEvent struct {
+	Version string                     `json:"version"`
+	Data    PayloadAttributesEventData `json:"data"`
+}
+
+// Event of the "blob_sidecar" topic: the node has received a valid blob sidecar, from P2P or the API.
+type BlobSidecarEvent struct {
+	BlockRoot     common.Root          `json:"block_root"`
+	Index         view.Uint64View      `json:"index"`
+	Slot          common.Slot          `json:"slot"`
+	KZGCommitment common.KZGCommitment `json:"kzg_commitment"`
+	VersionedHash common.Hash32        `json:"versioned_hash"`
+}
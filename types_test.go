@@ -0,0 +1,266 @@
+package eth2api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/protolambda/zrnt/eth2/beacon/altair"
+	"github.com/protolambda/zrnt/eth2/beacon/bellatrix"
+	"github.com/protolambda/zrnt/eth2/beacon/capella"
+	"github.com/protolambda/zrnt/eth2/beacon/common"
+	"github.com/protolambda/zrnt/eth2/beacon/deneb"
+	"github.com/protolambda/zrnt/eth2/beacon/electra"
+	"github.com/protolambda/zrnt/eth2/beacon/phase0"
+	"github.com/protolambda/zrnt/eth2/configs"
+	"github.com/protolambda/ztyp/codec"
+	"github.com/protolambda/ztyp/tree"
+	"github.com/protolambda/ztyp/view"
+)
+
+func TestVersionedBeaconStateFromTree(t *testing.T) {
+	spec := configs.Minimal
+	state, err := altair.AsBeaconStateView(altair.BeaconStateType(spec).New(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := state.SetSlot(123); err != nil {
+		t.Fatal(err)
+	}
+	var versioned VersionedBeaconState
+	if err := versioned.FromTree(spec, state); err != nil {
+		t.Fatal(err)
+	}
+	if versioned.Version != "altair" {
+		t.Fatalf("unexpected version: %q", versioned.Version)
+	}
+	if slot := versioned.Data.(*altair.BeaconState).Slot; slot != 123 {
+		t.Fatalf("unexpected slot: %d", slot)
+	}
+	back, err := versioned.Tree(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back.HashTreeRoot(tree.GetHashFn()) != state.HashTreeRoot(tree.GetHashFn()) {
+		t.Fatal("state did not round-trip")
+	}
+}
+
+func TestVersionedBeaconBlockDecodedVersion(t *testing.T) {
+	// the block type is selected by the version in the body, not by the version of the destination
+	var got VersionedBeaconBlock
+	if err := json.Unmarshal([]byte(`{"version": "altair", "data": {"slot": "123"}}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := got.Data.(*altair.BeaconBlock); !ok || b.Slot != 123 || got.Version != "altair" {
+		t.Fatalf("unexpected block: %q %T", got.Version, got.Data)
+	}
+	if err := json.Unmarshal([]byte(`{"version": "phase0", "data": {"slot": "124"}}`), &got); err != nil {
+		t.Fatal(err)
+	}
+	if b, ok := got.Data.(*phase0.BeaconBlock); !ok || b.Slot != 124 || got.Version != "phase0" {
+		t.Fatalf("unexpected block: %q %T", got.Version, got.Data)
+	}
+}
+
+func TestForks(t *testing.T) {
+	for _, f := range Forks {
+		got, err := ForkByName(strings.ToUpper(f.Name))
+		if err != nil || got != f {
+			t.Fatalf("fork %s: not found by name: %v", f.Name, err)
+		}
+		if version, err := SignedBeaconBlockVersion(f.SignedBeaconBlock()); err != nil || version != f.Name {
+			t.Errorf("fork %s: unexpected signed block version: %q, err: %v", f.Name, version, err)
+		}
+	}
+	if _, err := ForkByName("sharding"); err == nil {
+		t.Fatal("expected unknown fork")
+	}
+	if _, err := SignedBeaconBlockVersion(new(deneb.BeaconBlock)); err == nil {
+		t.Fatal("expected unsigned block to not have a signed block version")
+	}
+}
+
+func TestVersionedBeaconStateTreeForks(t *testing.T) {
+	spec := configs.Minimal
+	for name, alloc := range map[string]func() (common.BeaconState, error){
+		"deneb": func() (common.BeaconState, error) {
+			return deneb.AsBeaconStateView(deneb.BeaconStateType(spec).New(), nil)
+		},
+		"electra": func() (common.BeaconState, error) {
+			return electra.AsBeaconStateView(electra.BeaconStateType(spec).New(), nil)
+		},
+	} {
+		state, err := alloc()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var versioned VersionedBeaconState
+		if err := versioned.FromTree(spec, state); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if versioned.Version != name {
+			t.Fatalf("unexpected version: %q, expected %q", versioned.Version, name)
+		}
+		back, err := versioned.Tree(spec)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if back.HashTreeRoot(tree.GetHashFn()) != state.HashTreeRoot(tree.GetHashFn()) {
+			t.Fatalf("%s: state did not round-trip", name)
+		}
+	}
+}
+
+func TestBlockContents(t *testing.T) {
+	spec := configs.Minimal
+	block := &deneb.BeaconBlock{Slot: 42}
+	block.Body.SyncAggregate.SyncCommitteeBits = make(altair.SyncCommitteeBits, spec.SYNC_COMMITTEE_SIZE/8)
+	block.Body.BlobKZGCommitments = deneb.KZGCommitments{{0xc0}}
+	blob := make(Blob, BlobSize(spec))
+	blob[0] = 0xb1
+	contents := &VersionedBeaconBlock{Version: "deneb", Data: &BlockContents{
+		Block:     block,
+		KZGProofs: KZGProofs{{0xaa}},
+		Blobs:     Blobs{blob},
+	}}
+	root := contents.HashTreeRoot(spec, tree.GetHashFn())
+
+	data, err := json.Marshal(contents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON VersionedBeaconBlock
+	if err := json.Unmarshal(data, &fromJSON); err != nil {
+		t.Fatal(err)
+	}
+	got, ok := fromJSON.Data.(*BlockContents)
+	if !ok {
+		t.Fatalf("unexpected block contents type: %T", fromJSON.Data)
+	}
+	if got.Block.(*deneb.BeaconBlock).Slot != 42 || len(got.Blobs) != 1 || got.Blobs[0][0] != 0xb1 || got.KZGProofs[0][0] != 0xaa {
+		t.Fatalf("unexpected block contents: %v", got)
+	}
+	if fromJSON.HashTreeRoot(spec, tree.GetHashFn()) != root {
+		t.Fatal("block contents did not round-trip through JSON")
+	}
+
+	var buf bytes.Buffer
+	if err := contents.Serialize(spec, codec.NewEncodingWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	if uint64(buf.Len()) != contents.ByteLength(spec) {
+		t.Fatalf("unexpected byte length: %d, expected %d", contents.ByteLength(spec), buf.Len())
+	}
+	fromSSZ := VersionedBeaconBlock{Version: "deneb"}
+	if err := fromSSZ.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(buf.Bytes()), uint64(buf.Len()))); err != nil {
+		t.Fatal(err)
+	}
+	if fromSSZ.HashTreeRoot(spec, tree.GetHashFn()) != root {
+		t.Fatal("block contents did not round-trip through SSZ")
+	}
+}
+
+// SSZ types of each fork, to encode default objects with
+var forkTypes = map[string][3]func(spec *common.Spec) *view.ContainerTypeDef{
+	"phase0":    {phase0.BeaconBlockType, phase0.SignedBeaconBlockType, phase0.BeaconStateType},
+	"altair":    {altair.BeaconBlockType, altair.SignedBeaconBlockType, altair.BeaconStateType},
+	"bellatrix": {bellatrix.BeaconBlockType, bellatrix.SignedBeaconBlockType, bellatrix.BeaconStateType},
+	"capella":   {capella.BeaconBlockType, capella.SignedBeaconBlockType, capella.BeaconStateType},
+	"deneb":     {deneb.BeaconBlockType, deneb.SignedBeaconBlockType, deneb.BeaconStateType},
+	"electra":   {electra.BeaconBlockType, electra.SignedBeaconBlockType, electra.BeaconStateType},
+}
+
+func encodeSSZ(t *testing.T, spec *common.Spec, obj common.SpecObj) []byte {
+	var buf bytes.Buffer
+	if err := obj.Serialize(spec, codec.NewEncodingWriter(&buf)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestVersionedRoundTrip(t *testing.T) {
+	spec := configs.Minimal
+	for _, f := range Forks {
+		types, ok := forkTypes[f.Name]
+		if !ok {
+			t.Fatalf("no types for fork %s", f.Name)
+		}
+		t.Run(f.Name, func(t *testing.T) {
+			encoded := make([][]byte, 3)
+			for i, typ := range types {
+				var buf bytes.Buffer
+				if err := typ(spec).New().Serialize(codec.NewEncodingWriter(&buf)); err != nil {
+					t.Fatal(err)
+				}
+				encoded[i] = buf.Bytes()
+			}
+			block, signedBlock, state := encoded[0], encoded[1], encoded[2]
+			// since deneb, blocks are produced and published with blobs
+			if contents, ok := f.BlockContents().(*BlockContents); ok {
+				if err := contents.Block.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(block), uint64(len(block)))); err != nil {
+					t.Fatal(err)
+				}
+				contents.KZGProofs = KZGProofs{{0xaa}}
+				contents.Blobs = Blobs{make(Blob, BlobSize(spec))}
+				block = encodeSSZ(t, spec, contents)
+			}
+			signedContents := signedBlock
+			if contents, ok := f.SignedBlockContents().(*SignedBlockContents); ok {
+				if err := contents.SignedBlock.Deserialize(spec, codec.NewDecodingReader(bytes.NewReader(signedBlock), uint64(len(signedBlock)))); err != nil {
+					t.Fatal(err)
+				}
+				contents.KZGProofs = KZGProofs{{0xaa}}
+				contents.Blobs = Blobs{make(Blob, BlobSize(spec))}
+				signedContents = encodeSSZ(t, spec, contents)
+			}
+			for _, c := range []struct {
+				name    string
+				encoded []byte
+				alloc   func() VersionedSpecObj
+			}{
+				{"block", block, func() VersionedSpecObj { return new(VersionedBeaconBlock) }},
+				{"signed block", signedBlock, func() VersionedSpecObj { return new(VersionedSignedBeaconBlock) }},
+				{"signed block contents", signedContents, func() VersionedSpecObj { return new(VersionedSignedBlockContents) }},
+				{"state", state, func() VersionedSpecObj { return new(VersionedBeaconState) }},
+			} {
+				obj := c.alloc()
+				if err := UnmarshalVersionedSSZ(spec, strings.ToUpper(f.Name), c.encoded, obj); err != nil {
+					t.Fatalf("%s: failed to decode SSZ: %v", c.name, err)
+				}
+				version, data, err := MarshalVersionedSSZ(spec, obj)
+				if err != nil {
+					t.Fatalf("%s: failed to encode SSZ: %v", c.name, err)
+				}
+				if version != f.Name || !bytes.Equal(data, c.encoded) {
+					t.Fatalf("%s: SSZ did not round-trip, version: %q", c.name, version)
+				}
+
+				jsonData, err := json.Marshal(obj)
+				if err != nil {
+					t.Fatalf("%s: failed to encode JSON: %v", c.name, err)
+				}
+				fromJSON := c.alloc()
+				if err := json.Unmarshal(jsonData, fromJSON); err != nil {
+					t.Fatalf("%s: failed to decode JSON: %v", c.name, err)
+				}
+				if fromJSON.ConsensusVersion() != f.Name {
+					t.Fatalf("%s: unexpected version from JSON: %q", c.name, fromJSON.ConsensusVersion())
+				}
+				if _, data, err := MarshalVersionedSSZ(spec, fromJSON); err != nil || !bytes.Equal(data, c.encoded) {
+					t.Fatalf("%s: JSON did not round-trip, err: %v", c.name, err)
+				}
+			}
+		})
+	}
+}
+
+func TestVersionedMarshalJSON(t *testing.T) {
+	if _, err := json.Marshal(&VersionedSignedBeaconBlock{Version: "altair"}); err == nil {
+		t.Fatal("expected error for missing data")
+	}
+	if _, err := json.Marshal(&VersionedSignedBeaconBlock{Version: "sharding", Data: new(altair.SignedBeaconBlock)}); err == nil {
+		t.Fatal("expected error for unknown version")
+	}
+}